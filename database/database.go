@@ -75,6 +75,7 @@ type Database interface {
 	DropTable(values ...interface{}) Database
 	DropTableIfExists(values ...interface{}) Database
 	HasTable(value interface{}) bool
+	HasColumn(table, column string) bool
 	AutoMigrate(values ...interface{}) Database
 	ModifyColumn(column string, typ string) Database
 	DropColumn(column string) Database
@@ -116,7 +117,8 @@ func (it *Db) ChunkSize() int {
 	case "postgres":
 		return 3000
 	default:
-		return 100
+		// SQLite allows 999 variables per statement, keep room for wide tables like hits
+		return 50
 	}
 }
 
@@ -527,6 +529,10 @@ func (it *Db) HasTable(value interface{}) bool {
 	return it.Database.HasTable(value)
 }
 
+func (it *Db) HasColumn(table, column string) bool {
+	return it.Database.Dialect().HasColumn(table, column)
+}
+
 func (it *Db) AutoMigrate(values ...interface{}) Database {
 	if it.ReadOnly {
 		it.Database.Error = nil
@@ -660,6 +666,6 @@ func (it *Db) Between(t1 time.Time, t2 time.Time) Database {
 }
 
 type TimeValue struct {
-	Timeframe string `json:"timeframe"`
-	Amount    int64  `json:"amount"`
+	Timeframe string  `json:"timeframe"`
+	Amount    float64 `json:"amount"`
 }
@@ -58,6 +58,10 @@ var (
 			return By(fmt.Sprintf("cast(AVG(%s) as int) as amount", column))
 		}
 	}
+	// ByAverageFloat returns the average of the column without rounding it, such as a percentage
+	ByAverageFloat = func(column string) By {
+		return By(fmt.Sprintf("AVG(%s) as amount", column))
+	}
 )
 
 type TimeVar struct {
@@ -98,7 +102,7 @@ func (b *GroupQuery) ToTimeValue() (*TimeVar, error) {
 	var data []*TimeValue
 	for rows.Next() {
 		var timeframe string
-		var amount int64
+		var amount float64
 		if err := rows.Scan(&timeframe, &amount); err != nil {
 			log.Error(err, timeframe)
 		}
@@ -115,7 +119,7 @@ func (b *GroupQuery) ToTimeValue() (*TimeVar, error) {
 }
 
 func (t *TimeVar) FillMissing(current, end time.Time) ([]*TimeValue, error) {
-	timeMap := make(map[string]int64)
+	timeMap := make(map[string]float64)
 	var validSet []*TimeValue
 	for _, v := range t.data {
 		timeMap[v.Timeframe] = v.Amount
@@ -124,7 +128,7 @@ func (t *TimeVar) FillMissing(current, end time.Time) ([]*TimeValue, error) {
 	for {
 		currentStr := types.FixedTime(current, t.g.Group)
 
-		var amount int64
+		var amount float64
 		if timeMap[currentStr] != 0 {
 			amount = timeMap[currentStr]
 		}
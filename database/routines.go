@@ -14,7 +14,7 @@ var (
 	log = utils.Log.WithField("type", "database")
 )
 
-// Maintenance will automatically delete old records from 'failures' and 'hits'
+// Maintenance will automatically delete old records from 'failures', 'hits' and 'changes'
 // this function is currently set to delete records 7+ days old every 60 minutes
 // env: REMOVE_AFTER - golang duration parsed time for deleting records older than REMOVE_AFTER duration from now
 // env: CLEANUP_INTERVAL - golang duration parsed time for checking old records routine
@@ -36,6 +36,9 @@ func Maintenance() {
 			log.Infof("Deleting hits older than %s", deleteAfter.String())
 			deleteAllSince("hits", deleteAfter)
 
+			log.Infof("Deleting content changes older than %s", deleteAfter.String())
+			deleteChangesSince(deleteAfter)
+
 			ticker = interval
 		}
 	}
@@ -50,3 +53,13 @@ func deleteAllSince(table string, date time.Time) {
 		log.WithField("query", sql).Errorln(err)
 	}
 }
+
+// deleteChangesSince will delete content changes older than a time, the latest change of each
+// service is kept so the next check can still be compared to the previous version.
+func deleteChangesSince(date time.Time) {
+	sql := fmt.Sprintf("DELETE FROM changes WHERE created_at < '%s' AND id NOT IN (SELECT id FROM (SELECT MAX(id) AS id FROM changes GROUP BY service) AS latest)", database.FormatTime(date))
+	log.Info(sql)
+	if err := database.Exec(sql).Error(); err != nil {
+		log.WithField("query", sql).Errorln(err)
+	}
+}
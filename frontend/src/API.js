@@ -60,6 +60,14 @@ class Api {
     return axios.get('api/services/' + id + '/ping_data?start=' + start + '&end=' + end + '&group=' + group + '&fill=' + fill).then(response => (response.data))
   }
 
+  async service_timing(id, start, end, group, fill = true) {
+    return axios.get('api/services/' + id + '/timing_data?start=' + start + '&end=' + end + '&group=' + group + '&fill=' + fill).then(response => (response.data))
+  }
+
+  async service_icmp(id, start, end, group, fill = true) {
+    return axios.get('api/services/' + id + '/icmp_data?start=' + start + '&end=' + end + '&group=' + group + '&fill=' + fill).then(response => (response.data))
+  }
+
   async service_failures_data(id, start, end, group, fill = true) {
     return axios.get('api/services/' + id + '/failure_data?start=' + start + '&end=' + end + '&group=' + group + '&fill=' + fill).then(response => (response.data))
   }
@@ -76,6 +84,10 @@ class Api {
     return axios.get('api/services/' + id + '/failures?start=' + start + '&end=' + end + '&limit=' + limit + '&offset=' + offset).then(response => (response.data))
   }
 
+  async service_changes(id, start, end, limit = 999, offset = 0) {
+    return axios.get('api/services/' + id + '/changes?start=' + start + '&end=' + end + '&limit=' + limit + '&offset=' + offset).then(response => (response.data))
+  }
+
   async service_failures_delete(service) {
     return axios.delete('api/services/' + service.id + '/failures').then(response => (response.data))
   }
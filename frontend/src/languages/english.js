@@ -9,6 +9,7 @@ const english = {
     logout: "Logout",
     online: "Online",
     offline: "Offline",
+    degraded: "Degraded",
     configs: "Configuration",
     username: "Username",
     password: "Password",
@@ -3,6 +3,7 @@ package handlers
 import (
 	"github.com/gorilla/mux"
 	"github.com/statping-ng/statping-ng/database"
+	"github.com/statping-ng/statping-ng/types/changes"
 	"github.com/statping-ng/statping-ng/types/errors"
 	"github.com/statping-ng/statping-ng/types/failures"
 	"github.com/statping-ng/statping-ng/types/hits"
@@ -69,7 +70,7 @@ func apiCreateServiceHandler(w http.ResponseWriter, r *http.Request) {
 		sendErrorJson(err, w, r)
 		return
 	}
-	go services.ServiceCheckQueue(service, true)
+	services.ServiceCheckQueue(service, true)
 
 	sendJsonAction(service, "create", w, r)
 }
@@ -128,7 +129,7 @@ func apiServiceUpdateHandler(w http.ResponseWriter, r *http.Request) {
 		sendErrorJson(err, w, r)
 		return
 	}
-	go service.CheckService(true)
+	services.ServiceCheckNow(service)
 	sendJsonAction(service, "update", w, r)
 }
 
@@ -197,6 +198,59 @@ func apiServicePingDataHandler(w http.ResponseWriter, r *http.Request) {
 	returnJson(objs, w, r)
 }
 
+// timingColumns are the HTTP request phases stored on each hit
+var timingColumns = []string{"dns_lookup", "tcp_connect", "tls_handshake", "first_byte", "content_transfer"}
+
+func apiServiceTimingDataHandler(w http.ResponseWriter, r *http.Request) {
+	service, err := findService(r)
+	if err != nil {
+		sendErrorJson(err, w, r)
+		return
+	}
+
+	timing, err := columnsGraphData(r, service.AllHits().Timed(), timingColumns)
+	if err != nil {
+		sendErrorJson(err, w, r)
+		return
+	}
+	returnJson(timing, w, r)
+}
+
+// icmpColumns are the round trip statistics and packet loss stored on each hit of a ICMP service
+var icmpColumns = []string{"rtt_min", "rtt_avg", "rtt_max", "jitter", "packet_loss"}
+
+func apiServiceIcmpDataHandler(w http.ResponseWriter, r *http.Request) {
+	service, err := findService(r)
+	if err != nil {
+		sendErrorJson(err, w, r)
+		return
+	}
+
+	icmp, err := columnsGraphData(r, service.AllHits().Pinged(), icmpColumns)
+	if err != nil {
+		sendErrorJson(err, w, r)
+		return
+	}
+	returnJson(icmp, w, r)
+}
+
+// columnsGraphData returns the unrounded average of each column of the hits grouped by time, keyed by the column name
+func columnsGraphData(r *http.Request, allHits hits.Hitters, columns []string) (map[string][]*database.TimeValue, error) {
+	data := make(map[string][]*database.TimeValue)
+	for _, column := range columns {
+		groupQuery, err := database.ParseQueries(r, allHits)
+		if err != nil {
+			return nil, err
+		}
+		objs, err := groupQuery.GraphData(database.ByAverageFloat(column))
+		if err != nil {
+			return nil, err
+		}
+		data[column] = objs
+	}
+	return data, nil
+}
+
 func apiServiceTimeDataHandler(w http.ResponseWriter, r *http.Request) {
 	service, err := findService(r)
 	if err != nil {
@@ -304,6 +358,20 @@ func apiServiceFailuresHandler(r *http.Request) interface{} {
 	return fails
 }
 
+func apiServiceChangesHandler(r *http.Request) interface{} {
+	service, err := findService(r)
+	if err != nil {
+		return err
+	}
+	var chngs []*changes.Change
+	query, err := database.ParseQueries(r, service.AllChanges())
+	if err != nil {
+		return err
+	}
+	query.Find(&chngs)
+	return chngs
+}
+
 func apiServiceHitsHandler(r *http.Request) interface{} {
 	service, err := findService(r)
 	if err != nil {
@@ -6,6 +6,8 @@ import (
 	"github.com/pkg/errors"
 	"github.com/statping-ng/statping-ng/types"
 	"github.com/statping-ng/statping-ng/types/core"
+	"github.com/statping-ng/statping-ng/types/failures"
+	"github.com/statping-ng/statping-ng/types/hits"
 	"github.com/statping-ng/statping-ng/types/services"
 	"github.com/statping-ng/statping-ng/utils"
 	"github.com/stretchr/testify/assert"
@@ -161,6 +163,75 @@ func TestApiServiceRoutes(t *testing.T) {
 			ResponseLen:    1,
 			ExpectedStatus: 200,
 		},
+		{
+			Name:             "Statping Service Failures with Diagnostics",
+			URL:              "/api/services/1/failures?api=" + core.App.ApiSecret,
+			Method:           "GET",
+			ExpectedContains: []string{`"diagnostics":{"host":"google.com"`},
+			ExpectedStatus:   200,
+			BeforeTest: func(t *testing.T) error {
+				fail := &failures.Failure{
+					Service:     1,
+					Issue:       "connection refused",
+					Diagnostics: &failures.Diagnostics{Host: "google.com", ResolvedIP: "172.217.3.110"},
+					CreatedAt:   utils.Now().Add(-time.Minute),
+				}
+				return fail.Create()
+			},
+		},
+		{
+			Name:                "Statping Service Failures Diagnostics Unauthenticated",
+			URL:                 "/api/services/1/failures",
+			Method:              "GET",
+			ExpectedNotContains: []string{`"diagnostics"`, `172.217.3.110`},
+			ExpectedStatus:      200,
+		},
+		{
+			Name:             "Statping Service Hits with Steps",
+			URL:              fmt.Sprintf("/api/services/1/hits?start=%d&api=%s", utils.Now().Add(-5*time.Minute).Unix(), core.App.ApiSecret),
+			Method:           "GET",
+			ExpectedContains: []string{`"steps":[{"name":"Login","latency":1234}]`},
+			ExpectedStatus:   200,
+			BeforeTest: func(t *testing.T) error {
+				hit := &hits.Hit{
+					Service:   1,
+					Latency:   1234,
+					Steps:     hits.StepLatencies{{Name: "Login", Latency: 1234}},
+					CreatedAt: utils.Now().Add(-time.Minute),
+				}
+				return hit.Create()
+			},
+		},
+		{
+			Name:                "Statping Service Hits Steps Unauthenticated",
+			URL:                 fmt.Sprintf("/api/services/1/hits?start=%d", utils.Now().Add(-5*time.Minute).Unix()),
+			Method:              "GET",
+			ExpectedNotContains: []string{`"steps"`, `"Login"`},
+			ExpectedStatus:      200,
+		},
+		{
+			Name:             "Statping Service Hits with Perfdata",
+			URL:              fmt.Sprintf("/api/services/1/hits?start=%d&api=%s", utils.Now().Add(-5*time.Minute).Unix(), core.App.ApiSecret),
+			Method:           "GET",
+			ExpectedContains: []string{`"perfdata":[{"label":"load1","value":0.42}]`},
+			ExpectedStatus:   200,
+			BeforeTest: func(t *testing.T) error {
+				hit := &hits.Hit{
+					Service:   1,
+					Latency:   2500,
+					Perfdata:  hits.Perfdata{{Label: "load1", Value: 0.42}},
+					CreatedAt: utils.Now().Add(-time.Minute),
+				}
+				return hit.Create()
+			},
+		},
+		{
+			Name:                "Statping Service Hits Perfdata Unauthenticated",
+			URL:                 fmt.Sprintf("/api/services/1/hits?start=%d", utils.Now().Add(-5*time.Minute).Unix()),
+			Method:              "GET",
+			ExpectedNotContains: []string{`"perfdata"`, `"load1"`},
+			ExpectedStatus:      200,
+		},
 		{
 			Name:           "Statping Service 1 Hits Data",
 			URL:            "/api/services/1/hits_data" + startEndQuery,
@@ -175,6 +246,40 @@ func TestApiServiceRoutes(t *testing.T) {
 			ExpectedStatus: 200,
 			GreaterThan:    70,
 		},
+		{
+			Name:             "Statping Service 1 Timing Data",
+			URL:              "/api/services/1/timing_data" + startEndQuery,
+			Method:           "GET",
+			ExpectedStatus:   200,
+			ExpectedContains: []string{`"dns_lookup":`, `"tcp_connect":`, `"tls_handshake":`, `"first_byte":`, `"content_transfer":`},
+		},
+		{
+			Name:             "Statping Service 1 ICMP Data",
+			URL:              "/api/services/1/icmp_data" + startEndQuery,
+			Method:           "GET",
+			ExpectedStatus:   200,
+			ExpectedContains: []string{`"rtt_min":`, `"rtt_avg":`, `"rtt_max":`, `"jitter":`, `"packet_loss":`},
+		},
+		{
+			Name:             "Statping Service 1 ICMP Packet Loss",
+			URL:              "/api/services/1/icmp_data" + startEndQuery,
+			Method:           "GET",
+			ExpectedStatus:   200,
+			ExpectedContains: []string{`"amount":12.5}`, `"amount":100}`},
+			BeforeTest: func(t *testing.T) error {
+				pinged := []*hits.Hit{
+					{Service: 1, RttMin: 900, RttAvg: 1000, RttMax: 1100, PacketLoss: 12.5, CreatedAt: utils.Now().Add(-3 * time.Hour)},
+					// every packet was lost, there are no round trip statistics
+					{Service: 1, PacketLoss: 100, CreatedAt: utils.Now().Add(-2 * time.Hour)},
+				}
+				for _, hit := range pinged {
+					if err := hit.Create(); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
 		{
 			Name:           "Statping Service 1 Failure Data - 24 Hour",
 			URL:            "/api/services/1/failure_data" + startEndQuery + "&group=24h",
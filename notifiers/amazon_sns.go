@@ -131,6 +131,11 @@ func (g *amazonSNS) OnFailure(s services.Service, f failures.Failure) (string, e
 	return g.sendMessage(msg, s, f)
 }
 
+// OnWarning will trigger a warning for an online service
+func (g *amazonSNS) OnWarning(s services.Service, f failures.Failure) (string, error) {
+	return g.sendMessage(warningMessage(s, f), s, f)
+}
+
 // OnSuccess will trigger successful service
 func (g *amazonSNS) OnSuccess(s services.Service) (string, error) {
 	msg := ReplaceVars(g.SuccessData.String, s, failures.Failure{})
@@ -68,6 +68,11 @@ func TestAmazonSNSNotifier(t *testing.T) {
 		assert.Nil(t, err)
 	})
 
+	t.Run("SNS OnWarning", func(t *testing.T) {
+		_, err := AmazonSNS.OnWarning(services.Example(true), failures.ExampleWarning())
+		assert.Nil(t, err)
+	})
+
 	t.Run("SNS Test", func(t *testing.T) {
 		_, err := AmazonSNS.OnTest()
 		assert.Nil(t, err)
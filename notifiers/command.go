@@ -68,6 +68,11 @@ func (c *commandLine) OnFailure(s services.Service, f failures.Failure) (string,
 	return out, err
 }
 
+// OnWarning for commandLine does nothing, commands are only run on success and failure
+func (c *commandLine) OnWarning(s services.Service, f failures.Failure) (string, error) {
+	return "", nil
+}
+
 // OnTest for commandLine triggers when this notifier has been saved
 func (c *commandLine) OnTest() (string, error) {
 	tmpl := ReplaceVars(c.Var1.String, services.Example(true), failures.Example())
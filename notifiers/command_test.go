@@ -59,6 +59,11 @@ func TestCommandNotifier(t *testing.T) {
 		assert.Nil(t, err)
 	})
 
+	t.Run("Command OnWarning", func(t *testing.T) {
+		_, err := Command.OnWarning(services.Example(true), failures.ExampleWarning())
+		assert.Nil(t, err)
+	})
+
 	t.Run("Command Test", func(t *testing.T) {
 		_, err := Command.OnTest()
 		assert.Nil(t, err)
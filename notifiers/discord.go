@@ -61,6 +61,13 @@ func (d *discord) OnFailure(s services.Service, f failures.Failure) (string, err
 	return out, err
 }
 
+// OnWarning will trigger a warning for an online service
+func (d *discord) OnWarning(s services.Service, f failures.Failure) (string, error) {
+	data, _ := json.Marshal(map[string]string{"content": warningMessage(s, f)})
+	out, err := d.sendRequest(string(data))
+	return out, err
+}
+
 // OnSuccess will trigger successful service
 func (d *discord) OnSuccess(s services.Service) (string, error) {
 	out, err := d.sendRequest(ReplaceVars(d.SuccessData.String, s, failures.Failure{}))
@@ -65,6 +65,11 @@ func TestDiscordNotifier(t *testing.T) {
 		assert.Nil(t, err)
 	})
 
+	t.Run("discord OnWarning", func(t *testing.T) {
+		_, err := Discorder.OnWarning(services.Example(true), failures.ExampleWarning())
+		assert.Nil(t, err)
+	})
+
 	t.Run("discord Test", func(t *testing.T) {
 		_, err := Discorder.OnTest()
 		assert.Nil(t, err)
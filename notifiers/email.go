@@ -3,6 +3,7 @@ package notifiers
 import (
 	"crypto/tls"
 	"fmt"
+	"html"
 
 	"github.com/go-mail/mail"
 	"github.com/statping-ng/emails"
@@ -103,6 +104,17 @@ func (e *emailer) OnFailure(s services.Service, f failures.Failure) (string, err
 	return tmpl, e.dialSend(email)
 }
 
+// OnWarning will trigger a warning for an online service
+func (e *emailer) OnWarning(s services.Service, f failures.Failure) (string, error) {
+	email := &emailOutgoing{
+		To:       e.Var2.String,
+		Subject:  fmt.Sprintf("Service %s Warning", s.Name),
+		Template: html.EscapeString(warningMessage(s, f)),
+		From:     e.Var1.String,
+	}
+	return email.Template, e.dialSend(email)
+}
+
 // OnSuccess will trigger successful service
 func (e *emailer) OnSuccess(s services.Service) (string, error) {
 	subscriber := e.Var2.String
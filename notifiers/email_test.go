@@ -90,6 +90,11 @@ func TestEmailNotifier(t *testing.T) {
 		assert.Nil(t, err)
 	})
 
+	t.Run("email OnWarning", func(t *testing.T) {
+		_, err := email.OnWarning(services.Example(true), failures.ExampleWarning())
+		assert.Nil(t, err)
+	})
+
 	t.Run("email Test", func(t *testing.T) {
 		_, err := email.OnTest()
 		assert.Nil(t, err)
@@ -1,6 +1,7 @@
 package notifiers
 
 import (
+	"encoding/json"
 	"strings"
 	"time"
 
@@ -78,6 +79,13 @@ func (g *gotify) OnFailure(s services.Service, f failures.Failure) (string, erro
 	return out, err
 }
 
+// OnWarning will trigger a warning for an online service
+func (g *gotify) OnWarning(s services.Service, f failures.Failure) (string, error) {
+	data, _ := json.Marshal(map[string]interface{}{"title": s.Name, "message": warningMessage(s, f), "priority": 3})
+	out, err := g.sendMessage(string(data))
+	return out, err
+}
+
 // OnSuccess will trigger successful service
 func (g *gotify) OnSuccess(s services.Service) (string, error) {
 	out, err := g.sendMessage(ReplaceVars(g.SuccessData.String, s, failures.Failure{}))
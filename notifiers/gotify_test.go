@@ -73,6 +73,11 @@ func TestGotifyNotifier(t *testing.T) {
 		assert.Nil(t, err)
 	})
 
+	t.Run("gotify OnWarning", func(t *testing.T) {
+		_, err := Gotify.OnWarning(services.Example(true), failures.ExampleWarning())
+		assert.Nil(t, err)
+	})
+
 	t.Run("gotify Test", func(t *testing.T) {
 		_, err := Gotify.OnTest()
 		assert.Nil(t, err)
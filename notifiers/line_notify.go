@@ -62,6 +62,12 @@ func (l *lineNotifier) OnFailure(s services.Service, f failures.Failure) (string
 	return out, err
 }
 
+// OnWarning will trigger a warning for an online service
+func (l *lineNotifier) OnWarning(s services.Service, f failures.Failure) (string, error) {
+	out, err := l.sendMessage(warningMessage(s, f))
+	return out, err
+}
+
 // OnSuccess will trigger successful service
 func (l *lineNotifier) OnSuccess(s services.Service) (string, error) {
 	msg := fmt.Sprintf("Service %s is online!", s.Name)
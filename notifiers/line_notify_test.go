@@ -0,0 +1,72 @@
+package notifiers
+
+import (
+	"github.com/statping-ng/statping-ng/database"
+	"github.com/statping-ng/statping-ng/types/core"
+	"github.com/statping-ng/statping-ng/types/failures"
+	"github.com/statping-ng/statping-ng/types/notifications"
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/statping-ng/statping-ng/types/services"
+	"github.com/statping-ng/statping-ng/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+var (
+	LINE_NOTIFY_TOKEN string
+)
+
+func TestLineNotifier(t *testing.T) {
+	err := utils.InitLogs()
+	require.Nil(t, err)
+
+	t.Parallel()
+	LINE_NOTIFY_TOKEN = utils.Params.GetString("LINE_NOTIFY_TOKEN")
+
+	db, err := database.OpenTester()
+	require.Nil(t, err)
+	db.AutoMigrate(&notifications.Notification{})
+	notifications.SetDB(db)
+	core.Example()
+
+	if LINE_NOTIFY_TOKEN == "" {
+		t.Log("LINE Notify notifier testing skipped, missing LINE_NOTIFY_TOKEN environment variable")
+		t.SkipNow()
+	}
+
+	t.Run("Load LINE Notify", func(t *testing.T) {
+		LineNotify.ApiSecret = null.NewNullString(LINE_NOTIFY_TOKEN)
+		LineNotify.Enabled = null.NewNullBool(true)
+
+		Add(LineNotify)
+
+		assert.Equal(t, "Kanin Peanviriyakulkit", LineNotify.Author)
+		assert.Equal(t, LINE_NOTIFY_TOKEN, LineNotify.ApiSecret.String)
+	})
+
+	t.Run("LINE Notify Within Limits", func(t *testing.T) {
+		assert.True(t, LineNotify.CanSend())
+	})
+
+	t.Run("LINE Notify OnFailure", func(t *testing.T) {
+		_, err := LineNotify.OnFailure(services.Example(false), failures.Example())
+		assert.Nil(t, err)
+	})
+
+	t.Run("LINE Notify OnSuccess", func(t *testing.T) {
+		_, err := LineNotify.OnSuccess(services.Example(true))
+		assert.Nil(t, err)
+	})
+
+	t.Run("LINE Notify OnWarning", func(t *testing.T) {
+		_, err := LineNotify.OnWarning(services.Example(true), failures.ExampleWarning())
+		assert.Nil(t, err)
+	})
+
+	t.Run("LINE Notify Test", func(t *testing.T) {
+		_, err := LineNotify.OnTest()
+		assert.Nil(t, err)
+	})
+
+}
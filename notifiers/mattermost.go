@@ -2,6 +2,7 @@ package notifiers
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"strings"
 	"time"
@@ -81,6 +82,13 @@ func (s *mattermost) OnFailure(srv services.Service, f failures.Failure) (string
 	return out, err
 }
 
+// OnWarning will trigger a warning for an online service
+func (s *mattermost) OnWarning(srv services.Service, f failures.Failure) (string, error) {
+	data, _ := json.Marshal(map[string]string{"icon_emoji": ":warning:", "text": warningMessage(srv, f)})
+	out, err := s.sendMattermost(string(data))
+	return out, err
+}
+
 // OnSuccess will trigger successful service
 func (s *mattermost) OnSuccess(srv services.Service) (string, error) {
 	msg := ReplaceVars(s.SuccessData.String, srv, failures.Failure{})
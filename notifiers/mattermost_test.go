@@ -67,4 +67,9 @@ func TestMattermostNotifier(t *testing.T) {
 		assert.Nil(t, err)
 	})
 
+	t.Run("mattermost OnWarning", func(t *testing.T) {
+		_, err := mattermoster.OnWarning(services.Example(true), failures.ExampleWarning())
+		assert.Nil(t, err)
+	})
+
 }
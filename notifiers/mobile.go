@@ -75,6 +75,17 @@ func (m *mobilePush) OnFailure(s services.Service, f failures.Failure) (string,
 	return "notification sent", m.Send(msg)
 }
 
+// OnWarning will trigger a warning for an online service
+func (m *mobilePush) OnWarning(s services.Service, f failures.Failure) (string, error) {
+	data := dataJson(s, f)
+	msg := &pushArray{
+		Message: warningMessage(s, f),
+		Title:   "Service Warning",
+		Data:    data,
+	}
+	return "notification sent", m.Send(msg)
+}
+
 // OnSuccess will trigger successful service
 func (m *mobilePush) OnSuccess(s services.Service) (string, error) {
 	data := dataJson(s, failures.Failure{})
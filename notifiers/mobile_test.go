@@ -69,6 +69,11 @@ func TestMobileNotifier(t *testing.T) {
 		assert.Nil(t, err)
 	})
 
+	t.Run("Mobile OnWarning", func(t *testing.T) {
+		_, err := Mobile.OnWarning(services.Example(true), failures.ExampleWarning())
+		assert.Nil(t, err)
+	})
+
 	t.Run("Mobile Test", func(t *testing.T) {
 		_, err := Mobile.OnTest()
 		assert.Nil(t, err)
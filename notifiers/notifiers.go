@@ -2,6 +2,7 @@ package notifiers
 
 import (
 	"bytes"
+	"fmt"
 	"html/template"
 	"time"
 
@@ -68,6 +69,11 @@ func Add(notifs ...services.ServiceNotifier) {
 	}
 }
 
+// warningMessage returns a plain text message for a warning on a service that is still online
+func warningMessage(s services.Service, f failures.Failure) string {
+	return fmt.Sprintf("Warning for service '%s': %s", s.Name, f.Issue)
+}
+
 func ReplaceVars(input string, s services.Service, f failures.Failure) string {
 	return ReplaceTemplate(input, replacer{Service: s, Failure: f, Core: *core.App})
 }
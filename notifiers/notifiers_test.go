@@ -19,6 +19,12 @@ func TestReplaceTemplate(t *testing.T) {
 	assert.Equal(t, `{"id":6283,"name":"Statping Example","failure":"Response did not response a 200 status code"}`, replaced)
 }
 
+func TestWarningMessage(t *testing.T) {
+	t.Parallel()
+	msg := warningMessage(services.Example(true), failures.ExampleWarning())
+	assert.Equal(t, "Warning for service 'Statping Example': Certificate for statping.com expires in 5 days", msg)
+}
+
 func TestPushover_Select(t *testing.T) {
 	tests := []struct {
 		Value    string
@@ -117,6 +117,12 @@ func (t *pushover) OnFailure(s services.Service, f failures.Failure) (string, er
 	return out, err
 }
 
+// OnWarning will trigger a warning for an online service
+func (t *pushover) OnWarning(s services.Service, f failures.Failure) (string, error) {
+	out, err := t.sendMessage(warningMessage(s, f))
+	return out, err
+}
+
 // OnSuccess will trigger successful service
 func (t *pushover) OnSuccess(s services.Service) (string, error) {
 	message := ReplaceVars(t.SuccessData.String, s, failures.Failure{})
@@ -70,6 +70,11 @@ func TestPushoverNotifier(t *testing.T) {
 		assert.Nil(t, err)
 	})
 
+	t.Run("Pushover OnWarning", func(t *testing.T) {
+		_, err := Pushover.OnWarning(services.Example(true), failures.ExampleWarning())
+		assert.Nil(t, err)
+	})
+
 	t.Run("Pushover Test", func(t *testing.T) {
 		_, err := Pushover.OnTest()
 		assert.Nil(t, err)
@@ -2,6 +2,7 @@ package notifiers
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"strings"
 	"time"
@@ -81,6 +82,13 @@ func (s *slack) OnFailure(srv services.Service, f failures.Failure) (string, err
 	return out, err
 }
 
+// OnWarning will trigger a warning for an online service
+func (s *slack) OnWarning(srv services.Service, f failures.Failure) (string, error) {
+	data, _ := json.Marshal(map[string]string{"text": ":warning: " + warningMessage(srv, f)})
+	out, err := s.sendSlack(string(data))
+	return out, err
+}
+
 // OnSuccess will trigger successful service
 func (s *slack) OnSuccess(srv services.Service) (string, error) {
 	msg := ReplaceVars(s.SuccessData.String, srv, failures.Failure{})
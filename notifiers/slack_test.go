@@ -67,4 +67,9 @@ func TestSlackNotifier(t *testing.T) {
 		assert.Nil(t, err)
 	})
 
+	t.Run("slack OnWarning", func(t *testing.T) {
+		_, err := slacker.OnWarning(services.Example(true), failures.ExampleWarning())
+		assert.Nil(t, err)
+	})
+
 }
@@ -84,6 +84,11 @@ func (t *telegram) OnFailure(s services.Service, f failures.Failure) (string, er
 	return t.sendMessage(msg)
 }
 
+// OnWarning will trigger a warning for an online service
+func (t *telegram) OnWarning(s services.Service, f failures.Failure) (string, error) {
+	return t.sendMessage(warningMessage(s, f))
+}
+
 // OnSuccess will trigger successful service
 func (t *telegram) OnSuccess(s services.Service) (string, error) {
 	msg := ReplaceVars(t.SuccessData.String, s, failures.Failure{})
@@ -73,6 +73,11 @@ func TestTelegramNotifier(t *testing.T) {
 		assert.Nil(t, err)
 	})
 
+	t.Run("Telegram OnWarning", func(t *testing.T) {
+		_, err := Telegram.OnWarning(services.Example(true), failures.ExampleWarning())
+		assert.Nil(t, err)
+	})
+
 	t.Run("Telegram Test", func(t *testing.T) {
 		_, err := Telegram.OnTest()
 		assert.Nil(t, err)
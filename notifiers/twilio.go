@@ -101,6 +101,11 @@ func (t *twilio) OnFailure(s services.Service, f failures.Failure) (string, erro
 	return t.sendMessage(msg)
 }
 
+// OnWarning will trigger a warning for an online service
+func (t *twilio) OnWarning(s services.Service, f failures.Failure) (string, error) {
+	return t.sendMessage(warningMessage(s, f))
+}
+
 // OnSuccess will trigger successful service
 func (t *twilio) OnSuccess(s services.Service) (string, error) {
 	msg := ReplaceVars(t.SuccessData.String, s, failures.Failure{})
@@ -73,6 +73,11 @@ func TestTwilioNotifier(t *testing.T) {
 		assert.Nil(t, err)
 	})
 
+	t.Run("Twilio OnWarning", func(t *testing.T) {
+		_, err := Twilio.OnWarning(services.Example(true), failures.ExampleWarning())
+		assert.Nil(t, err)
+	})
+
 	t.Run("Twilio Test", func(t *testing.T) {
 		_, err := Twilio.OnTest()
 		assert.Nil(t, err)
@@ -2,6 +2,7 @@ package notifiers
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -156,6 +157,18 @@ func (w *webhooker) OnFailure(s services.Service, f failures.Failure) (string, e
 	return string(content), err
 }
 
+// OnWarning will trigger a warning for an online service
+func (w *webhooker) OnWarning(s services.Service, f failures.Failure) (string, error) {
+	data, _ := json.Marshal(map[string]interface{}{"id": utils.ToString(s.Id), "online": s.Online, "warning": f.Issue, "reason": f.Reason})
+	resp, err := w.sendHttpWebhook(string(data))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	content, err := ioutil.ReadAll(resp.Body)
+	return string(content), err
+}
+
 // OnSuccess will trigger successful service
 func (w *webhooker) OnSuccess(s services.Service) (string, error) {
 	msg := ReplaceVars(w.SuccessData.String, s, failures.Failure{})
@@ -67,6 +67,11 @@ func TestWebhookNotifier(t *testing.T) {
 		assert.Nil(t, err)
 	})
 
+	t.Run("webhooker OnWarning", func(t *testing.T) {
+		_, err := Webhook.OnWarning(services.Example(true), failures.ExampleWarning())
+		assert.Nil(t, err)
+	})
+
 	t.Run("webhooker Send", func(t *testing.T) {
 		err := Webhook.Send(fullMsg)
 		assert.Nil(t, err)
@@ -0,0 +1,38 @@
+package changes
+
+import (
+	"github.com/statping-ng/statping-ng/database"
+	"github.com/statping-ng/statping-ng/types/metrics"
+)
+
+var db database.Database
+
+func SetDB(database database.Database) {
+	db = database.Model(&Change{})
+}
+
+func DB() database.Database {
+	return db
+}
+
+func (c *Change) AfterFind() {
+	metrics.Query("change", "find")
+}
+
+func (c *Change) AfterDelete() {
+	metrics.Query("change", "delete")
+}
+
+func (c *Change) AfterCreate() {
+	metrics.Query("change", "create")
+}
+
+func (c *Change) Create() error {
+	q := db.Create(c)
+	return q.Error()
+}
+
+func (c *Change) Delete() error {
+	q := db.Delete(c)
+	return q.Error()
+}
@@ -0,0 +1,51 @@
+package changes
+
+import (
+	"fmt"
+
+	"github.com/statping-ng/statping-ng/database"
+)
+
+type ColumnIDInterfacer interface {
+	ChangesColumnID() (string, int64)
+}
+
+type Changer struct {
+	db database.Database
+}
+
+func (c Changer) Db() database.Database {
+	return c.db
+}
+
+// Last returns the latest Change, or nil if the content was never recorded
+func (c Changer) Last() *Change {
+	var changes []*Change
+	c.db.Order("id DESC").Limit(1).Find(&changes)
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes[0]
+}
+
+func (c Changer) List() []*Change {
+	var changes []*Change
+	c.db.Order("id DESC").Find(&changes)
+	return changes
+}
+
+func (c Changer) Count() int {
+	var amount int
+	c.db.Count(&amount)
+	return amount
+}
+
+func (c Changer) DeleteAll() error {
+	q := c.db.Delete(&Change{})
+	return q.Error()
+}
+
+func AllChanges(obj ColumnIDInterfacer) Changer {
+	column, id := obj.ChangesColumnID()
+	return Changer{db.Where(fmt.Sprintf("%s = ?", column), id)}
+}
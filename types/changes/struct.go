@@ -0,0 +1,16 @@
+package changes
+
+import "time"
+
+// Change is recorded each time the watched content of a service changes. The first Change of a service is the
+// version the content was compared against first, it has no diff.
+type Change struct {
+	Id        int64     `gorm:"primary_key;column:id" json:"id"`
+	Service   int64     `gorm:"index;column:service" json:"-"`
+	Hash      string    `gorm:"column:hash" json:"hash"`
+	Content   string    `gorm:"column:content;type:text" json:"content" scope:"user,admin"`
+	Diff      string    `gorm:"column:diff;type:text" json:"diff,omitempty" scope:"user,admin"`
+	Added     int       `gorm:"column:added" json:"added"`
+	Removed   int       `gorm:"column:removed" json:"removed"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/statping-ng/statping-ng/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"path/filepath"
 	"testing"
 )
 
@@ -25,6 +26,29 @@ func TestSQLiteConfig(t *testing.T) {
 	require.Nil(t, err)
 }
 
+func TestMigrateStaleExpected(t *testing.T) {
+	sqlite := &DbConfig{DbConn: "sqlite", SqlFile: filepath.Join(t.TempDir(), "migrate.db")}
+	require.Nil(t, Connect(sqlite, false))
+	defer sqlite.Close()
+
+	// a services table from before the TCP and UDP payload fields
+	require.Nil(t, sqlite.Db.Exec("CREATE TABLE services (id integer primary key autoincrement, name varchar(255), port integer not null, check_type varchar(255), expected text)").Error())
+	require.Nil(t, sqlite.Db.Exec("INSERT INTO services (name, port, check_type, expected) VALUES ('TCP', 22, 'tcp', 'stale'), ('UDP', 53, 'udp', 'stale'), ('HTTP', 0, 'http', 'ok')").Error())
+
+	require.Nil(t, sqlite.MigrateDatabase())
+
+	var expected []string
+	require.Nil(t, sqlite.Db.Table("services").Order("id").Pluck("expected", &expected).Error())
+	assert.Equal(t, []string{"", "", "ok"}, expected)
+
+	// the values are only cleared once
+	require.Nil(t, sqlite.Db.Exec("UPDATE services SET expected = 'banner' WHERE check_type = 'tcp'").Error())
+	require.Nil(t, sqlite.MigrateDatabase())
+	expected = nil
+	require.Nil(t, sqlite.Db.Table("services").Order("id").Pluck("expected", &expected).Error())
+	assert.Equal(t, []string{"banner", "", "ok"}, expected)
+}
+
 func TestMySQLConfig(t *testing.T) {
 	mysql := &DbConfig{
 		DbConn: "mysql",
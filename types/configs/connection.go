@@ -5,6 +5,7 @@ import (
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
 	"github.com/statping-ng/statping-ng/database"
+	"github.com/statping-ng/statping-ng/types/changes"
 	"github.com/statping-ng/statping-ng/types/checkins"
 	"github.com/statping-ng/statping-ng/types/core"
 	"github.com/statping-ng/statping-ng/types/failures"
@@ -26,6 +27,7 @@ func initModels(db database.Database) {
 	services.SetDB(db)
 	hits.SetDB(db)
 	failures.SetDB(db)
+	changes.SetDB(db)
 	checkins.SetDB(db)
 	notifications.SetDB(db)
 	incidents.SetDB(db)
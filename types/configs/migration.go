@@ -10,6 +10,7 @@ import (
 	"github.com/statping-ng/statping-ng/types/notifications"
 	"github.com/statping-ng/statping-ng/utils"
 
+	"github.com/statping-ng/statping-ng/types/changes"
 	"github.com/statping-ng/statping-ng/types/checkins"
 	"github.com/statping-ng/statping-ng/types/core"
 	"github.com/statping-ng/statping-ng/types/failures"
@@ -104,7 +105,11 @@ func (d *DbConfig) BackupAssets() error {
 //This function will NOT remove previous records, tables or columns from the database.
 //If this function has an issue, it will ROLLBACK to the previous state.
 func (d *DbConfig) MigrateDatabase() error {
-	var DbModels = []interface{}{&services.Service{}, &users.User{}, &hits.Hit{}, &failures.Failure{}, &messages.Message{}, &groups.Group{}, &checkins.Checkin{}, &checkins.CheckinHit{}, &notifications.Notification{}, &incidents.Incident{}, &incidents.IncidentUpdate{}}
+	var DbModels = []interface{}{&services.Service{}, &users.User{}, &hits.Hit{}, &failures.Failure{}, &changes.Change{}, &messages.Message{}, &groups.Group{}, &checkins.Checkin{}, &checkins.CheckinHit{}, &notifications.Notification{}, &incidents.Incident{}, &incidents.IncidentUpdate{}}
+
+	// the expected response of TCP and UDP services was ignored before the payload fields were added,
+	// clear the stale values of these services so they are not matched against the response
+	staleExpected := d.Db.HasTable("services") && !d.Db.HasColumn("services", "expected_prefix")
 
 	log.Infoln("Migrating Database Tables...")
 	tx := d.Db.Begin()
@@ -134,6 +139,12 @@ func (d *DbConfig) MigrateDatabase() error {
 
 	d.Db.Table("core").Model(&core.Core{}).Update("version", utils.Params.GetString("VERSION"))
 
+	if staleExpected {
+		if err := d.Db.Exec("UPDATE services SET expected = '' WHERE check_type IN ('tcp', 'udp')").Error(); err != nil {
+			log.Errorln(err)
+		}
+	}
+
 	log.Infoln("Statping Database Tables Migrated")
 
 	if err := d.Db.Model(&hits.Hit{}).AddIndex("idx_service_hit", "service").Error(); err != nil {
@@ -3,6 +3,7 @@ package failures
 import (
 	"github.com/statping-ng/statping-ng/database"
 	"github.com/statping-ng/statping-ng/utils"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
@@ -19,4 +20,23 @@ func TestInit(t *testing.T) {
 	t.Run("Test Samples", func(t *testing.T) {
 		require.Nil(t, Samples())
 	})
+
+	t.Run("Test Diagnostics", func(t *testing.T) {
+		diagnostics := &Diagnostics{
+			Host:          "example.com",
+			DnsAnswers:    []string{"93.184.216.34"},
+			ResolvedIP:    "93.184.216.34",
+			SourceAddress: "10.0.0.2",
+			Hops:          []Hop{{TTL: 1, Address: "10.0.0.1", Latency: 420}, {TTL: 2}, {TTL: 3, Address: "93.184.216.34", Latency: 9800, Reached: true}},
+		}
+		fail := &Failure{Service: 99, Issue: "connection refused", Diagnostics: diagnostics}
+		require.Nil(t, fail.Create())
+		require.Nil(t, (&Failure{Service: 99, Issue: "timeout"}).Create())
+
+		var found []*Failure
+		require.Nil(t, db.Where("service = ?", 99).Order("id").Find(&found).Error())
+		require.Len(t, found, 2)
+		assert.Equal(t, diagnostics, found[0].Diagnostics)
+		assert.Nil(t, found[1].Diagnostics)
+	})
 }
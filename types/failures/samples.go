@@ -27,6 +27,17 @@ func Example() Failure {
 	}
 }
 
+// ExampleWarning returns a warning for a service that is online, such as an expiring certificate
+func ExampleWarning() Failure {
+	return Failure{
+		Id:        48534,
+		Issue:     "Certificate for statping.com expires in 5 days",
+		Service:   1,
+		Reason:    "certificate_expiring",
+		CreatedAt: utils.Now(),
+	}
+}
+
 func Samples() error {
 	log.Infoln("Inserting Sample Service Failures...")
 	createdAt := utils.Now().Add(-3 * types.Day)
@@ -1,20 +1,79 @@
 package failures
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 // Failure is a failed attempt to check a service. Any a service does not meet the expected requirements,
 // a new Failure will be inserted into Db.
 type Failure struct {
-	Id        int64     `gorm:"primary_key;column:id" json:"id"`
-	Issue     string    `gorm:"column:issue" json:"issue"`
-	Method    string    `gorm:"column:method" json:"method,omitempty"`
-	MethodId  int64     `gorm:"column:method_id" json:"method_id,omitempty"`
-	ErrorCode int       `gorm:"column:error_code" json:"error_code"`
-	Service   int64     `gorm:"index;column:service" json:"-"`
-	Checkin   int64     `gorm:"index;column:checkin" json:"-"`
-	PingTime  int64     `gorm:"column:ping_time"  json:"ping"`
-	Reason    string    `gorm:"column:reason" json:"reason,omitempty"`
-	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	Id          int64        `gorm:"primary_key;column:id" json:"id"`
+	Issue       string       `gorm:"column:issue" json:"issue"`
+	Method      string       `gorm:"column:method" json:"method,omitempty"`
+	MethodId    int64        `gorm:"column:method_id" json:"method_id,omitempty"`
+	ErrorCode   int          `gorm:"column:error_code" json:"error_code"`
+	Service     int64        `gorm:"index;column:service" json:"-"`
+	Checkin     int64        `gorm:"index;column:checkin" json:"-"`
+	PingTime    int64        `gorm:"column:ping_time"  json:"ping"`
+	Reason      string       `gorm:"column:reason" json:"reason,omitempty"`
+	Attempts    int          `gorm:"column:attempts" json:"attempts,omitempty"`
+	Diagnostics *Diagnostics `gorm:"column:diagnostics;type:text" json:"diagnostics,omitempty" scope:"user,admin"`
+	CreatedAt   time.Time    `gorm:"column:created_at" json:"created_at"`
+}
+
+// Diagnostics describe the network path to a service when it went offline: the answers of the system
+// resolver, the IP address that was traced, the local source address and the hops to the service.
+type Diagnostics struct {
+	Host          string   `json:"host"`
+	DnsAnswers    []string `json:"dns_answers,omitempty"`
+	DnsError      string   `json:"dns_error,omitempty"`
+	ResolvedIP    string   `json:"resolved_ip,omitempty"`
+	SourceAddress string   `json:"source_address,omitempty"`
+	Hops          []Hop    `json:"hops,omitempty"`
+	TraceError    string   `json:"trace_error,omitempty"`
+}
+
+// Hop is a router on the path to the service, the Address is empty if it didn't reply and the Latency is in microseconds
+type Hop struct {
+	TTL         int    `json:"ttl"`
+	Address     string `json:"address,omitempty"`
+	Latency     int64  `json:"latency,omitempty"`
+	Reached     bool   `json:"reached,omitempty"`
+	Unreachable bool   `json:"unreachable,omitempty"`
+}
+
+// Value for Diagnostics returns the JSON object, or NULL if no diagnostics were collected
+func (d *Diagnostics) Value() (driver.Value, error) {
+	if d == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan for Diagnostics decodes the JSON object from the 'diagnostics' column
+func (d *Diagnostics) Scan(value interface{}) error {
+	var data []byte
+	switch val := value.(type) {
+	case nil:
+		return nil
+	case string:
+		data = []byte(val)
+	case []byte:
+		data = val
+	default:
+		return fmt.Errorf("cannot scan %T into %T", value, d)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, d)
 }
 
 type FailSort []Failure
@@ -86,6 +86,17 @@ func (h Hitters) Avg() int64 {
 	return r.Amount
 }
 
+// Timed returns only the hits that have a HTTP timing breakdown
+func (h Hitters) Timed() Hitters {
+	return Hitters{h.db.Where("first_byte > 0")}
+}
+
+// Pinged returns only the hits that have ICMP round trip statistics or lost packets,
+// a check that lost every packet has no round trip time
+func (h Hitters) Pinged() Hitters {
+	return Hitters{h.db.Where("(rtt_avg > 0 OR packet_loss > 0)")}
+}
+
 func AllHits(obj ColumnIDInterfacer) Hitters {
 	column, id := obj.HitsColumnID()
 	return Hitters{db.Where(fmt.Sprintf("%s = ?", column), id)}
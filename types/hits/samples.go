@@ -15,14 +15,17 @@ var SampleHits = 99900.
 
 func Samples() error {
 	log.Infoln("Inserting Sample Service Hits...")
+	// a single transaction keeps SQLite from syncing the file after every chunk
+	tx := db.GormDB().Begin()
 	for i := int64(1); i <= 5; i++ {
 		records := createHitsAt(i)
-		if err := gormbulk.BulkInsert(db.GormDB(), records, db.ChunkSize()); err != nil {
+		if err := gormbulk.BulkInsert(tx, records, db.ChunkSize()); err != nil {
+			tx.Rollback()
 			log.Error(err)
 			return err
 		}
 	}
-	return nil
+	return tx.Commit().Error
 }
 
 func createHitsAt(serviceID int64) []interface{} {
@@ -1,14 +1,116 @@
 package hits
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 // Hit struct is a 'successful' ping or web response entry for a service.
+// HTTP services also store the duration of each phase of the request in microseconds,
+// ICMP services store the round trip statistics of the ping burst and the packet loss percentage,
+// command services store the performance data of the plugin. A hit with a ConfirmIP failed on the
+// address of the service and succeeded from the confirmation IP address.
 type Hit struct {
-	Id        int64     `gorm:"primary_key;column:id" json:"id"`
-	Service   int64     `gorm:"index;column:service" json:"-"`
-	Latency   int64     `gorm:"column:latency" json:"latency"`
-	PingTime  int64     `gorm:"column:ping_time" json:"ping_time"`
-	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	Id              int64         `gorm:"primary_key;column:id" json:"id"`
+	Service         int64         `gorm:"index;column:service" json:"-"`
+	Latency         int64         `gorm:"column:latency" json:"latency"`
+	PingTime        int64         `gorm:"column:ping_time" json:"ping_time"`
+	Degraded        bool          `gorm:"column:degraded" json:"degraded"`
+	DnsLookup       int64         `gorm:"column:dns_lookup" json:"dns_lookup"`
+	TcpConnect      int64         `gorm:"column:tcp_connect" json:"tcp_connect"`
+	TlsHandshake    int64         `gorm:"column:tls_handshake" json:"tls_handshake"`
+	FirstByte       int64         `gorm:"column:first_byte" json:"first_byte"`
+	ContentTransfer int64         `gorm:"column:content_transfer" json:"content_transfer"`
+	RttMin          int64         `gorm:"column:rtt_min" json:"rtt_min,omitempty"`
+	RttAvg          int64         `gorm:"column:rtt_avg" json:"rtt_avg,omitempty"`
+	RttMax          int64         `gorm:"column:rtt_max" json:"rtt_max,omitempty"`
+	Jitter          int64         `gorm:"column:jitter" json:"jitter,omitempty"`
+	PacketLoss      float64       `gorm:"column:packet_loss" json:"packet_loss,omitempty"`
+	Steps           StepLatencies `gorm:"column:steps;type:text" json:"steps,omitempty" scope:"user,admin"`
+	Perfdata        Perfdata      `gorm:"column:perfdata;type:text" json:"perfdata,omitempty" scope:"user,admin"`
+	ConfirmIP       string        `gorm:"column:confirm_ip" json:"confirm_ip,omitempty" scope:"user,admin"`
+	CreatedAt       time.Time     `gorm:"column:created_at" json:"created_at"`
+}
+
+// StepLatency is the latency of a single step for services that run multiple requests
+type StepLatency struct {
+	Name    string `json:"name"`
+	Latency int64  `json:"latency"`
+}
+
+// StepLatencies are stored as a JSON array in the 'steps' column
+type StepLatencies []StepLatency
+
+// Value for StepLatencies returns the JSON array, or NULL if there are no steps
+func (s StepLatencies) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	return jsonValue(s)
+}
+
+// Scan for StepLatencies decodes the JSON array from the 'steps' column
+func (s *StepLatencies) Scan(value interface{}) error {
+	*s = nil
+	return scanJSON(value, s)
+}
+
+// PerfMetric is a single metric of the Nagios performance data, such as 'time'=0.25s;1;2;0;10.
+// The thresholds, minimum and maximum are kept as written by the plugin since they can be ranges.
+type PerfMetric struct {
+	Label string  `json:"label"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit,omitempty"`
+	Warn  string  `json:"warn,omitempty"`
+	Crit  string  `json:"crit,omitempty"`
+	Min   string  `json:"min,omitempty"`
+	Max   string  `json:"max,omitempty"`
+}
+
+// Perfdata is stored as a JSON array in the 'perfdata' column
+type Perfdata []PerfMetric
+
+// Value for Perfdata returns the JSON array, or NULL if there are no metrics
+func (p Perfdata) Value() (driver.Value, error) {
+	if len(p) == 0 {
+		return nil, nil
+	}
+	return jsonValue(p)
+}
+
+// Scan for Perfdata decodes the JSON array from the 'perfdata' column
+func (p *Perfdata) Scan(value interface{}) error {
+	*p = nil
+	return scanJSON(value, p)
+}
+
+func jsonValue(v interface{}) (driver.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// scanJSON decodes a JSON column into v, NULL and empty values leave v untouched
+func scanJSON(value interface{}, v interface{}) error {
+	var data []byte
+	switch val := value.(type) {
+	case nil:
+		return nil
+	case string:
+		data = []byte(val)
+	case []byte:
+		data = val
+	default:
+		return fmt.Errorf("cannot scan %T into %T", value, v)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, v)
 }
 
 // BeforeCreate for Hit will set CreatedAt to UTC
@@ -44,6 +44,10 @@ func InitMetrics() {
 		httpDuration,
 		databaseStats,
 		queryStats,
+		schedulerLag,
+		schedulerStats,
+		serviceSkipped,
+		serviceOverran,
 	)
 }
 
@@ -79,6 +83,8 @@ func Inc(method string, labels ...interface{}) {
 		serviceFailures.WithLabelValues(convert(labels)...).Inc()
 	case "success":
 		serviceSuccess.WithLabelValues(convert(labels)...).Inc()
+	case "overran":
+		serviceOverran.WithLabelValues(convert(labels)...).Inc()
 	}
 }
 
@@ -88,6 +94,8 @@ func Add(method string, value float64, labels ...interface{}) {
 		serviceFailures.WithLabelValues(convert(labels)...).Add(value)
 	case "success":
 		serviceSuccess.WithLabelValues(convert(labels)...).Add(value)
+	case "skipped":
+		serviceSkipped.WithLabelValues(convert(labels)...).Add(value)
 	}
 }
 
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// time between the scheduled time of a check and a worker starting it
+	schedulerLag = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "statping",
+			Name:      "scheduler_lag_seconds",
+			Help:      "Delay between the scheduled time of a service check and the start of the check",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60},
+		},
+	)
+
+	// queued checks, running checks and workers of the scheduler
+	schedulerStats = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "statping",
+			Name:      "scheduler",
+			Help:      "Queued checks, running checks and workers of the service check scheduler",
+		}, []string{"metric"},
+	)
+
+	// checks skipped because the service fell behind its interval
+	serviceSkipped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "statping",
+			Name:      "service_checks_skipped",
+			Help:      "How many scheduled checks of a service were skipped because it fell behind its interval",
+		},
+		[]string{"service"},
+	)
+
+	// checks that took longer than the interval of the service
+	serviceOverran = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "statping",
+			Name:      "service_checks_overran",
+			Help:      "How many checks of a service took longer than its interval",
+		},
+		[]string{"service"},
+	)
+)
+
+func SchedulerLag(lag time.Duration) {
+	schedulerLag.Observe(lag.Seconds())
+}
+
+func SchedulerStats(queued, running, workers int) {
+	schedulerStats.WithLabelValues("queued").Set(float64(queued))
+	schedulerStats.WithLabelValues("running").Set(float64(running))
+	schedulerStats.WithLabelValues("workers").Set(float64(workers))
+}
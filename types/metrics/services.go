@@ -3,12 +3,12 @@ package metrics
 import "github.com/prometheus/client_golang/prometheus"
 
 var (
-	// service is online if set to 1, offline if 0
+	// service is online if set to 1, degraded if 0.5, offline if 0
 	serviceOnline = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "statping",
 			Name:      "service_online",
-			Help:      "If service is online",
+			Help:      "If service is online (1), degraded (0.5) or offline (0)",
 		},
 		[]string{"service", "type"},
 	)
@@ -9,6 +9,7 @@ import (
 type Notifier interface {
 	OnSuccess(services.Service) (string, error)                   // OnSuccess is triggered when a service is successful
 	OnFailure(services.Service, failures.Failure) (string, error) // OnFailure is triggered when a service is failing
+	OnWarning(services.Service, failures.Failure) (string, error) // OnWarning is triggered when a service is online but needs attention
 	OnTest() (string, error)                                      // OnTest is triggered for testing
 	OnSave() (string, error)                                      // OnSave is triggered for when saved
 }
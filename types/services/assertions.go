@@ -0,0 +1,249 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/statping-ng/statping-ng/types/errors"
+	"github.com/tidwall/gjson"
+)
+
+// Assertion is a single check against the response of a service. The Source selects which part of the
+// response is checked, the sources of each service type are:
+//
+//	http, grpc  status_code, header, json, body, body_size, response_time
+//	sql         row_count, value, response_time
+//	redis       role, master_link_status, connected_replicas, used_memory_percent, keyspace, info, response_time
+//	amqp        message_count, consumer_count, response_time
+//	ldap        entry_count, response_time
+//	icmp        packet_loss, rtt_min, rtt_avg, rtt_max, jitter, response_time
+//
+// Other types use the HTTP sources.
+// gRPC method calls check the gRPC status code, the metadata and the JSON response.
+// SQL services check the value of the first column.
+// ICMP round trip times are in milliseconds.
+// The Property is the header name for 'header', the gjson path for 'json', the database for 'keyspace'
+// and the field name for 'info' assertions.
+// A failed Soft assertion marks the service as degraded instead of offline.
+type Assertion struct {
+	Source     string `json:"source"`
+	Property   string `json:"property,omitempty"`
+	Comparison string `json:"comparison"`
+	Value      string `json:"value,omitempty"`
+	Soft       bool   `json:"soft,omitempty"`
+}
+
+// assertionResponse contains the parts of a response that assertions are checked against
+type assertionResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Latency    time.Duration
+	Rows       int
+	Value      string
+	HasValue   bool
+	Info       map[string]string
+}
+
+// assertionSources are the sources that can be checked for each service type, other types use the HTTP sources
+var assertionSources = map[string]map[string]bool{
+	"http":  {"status_code": true, "header": true, "json": true, "body": true, "body_size": true, "response_time": true},
+	"grpc":  {"status_code": true, "header": true, "json": true, "body": true, "body_size": true, "response_time": true},
+	"sql":   {"row_count": true, "value": true, "response_time": true},
+	"redis": {"role": true, "master_link_status": true, "connected_replicas": true, "used_memory_percent": true, "keyspace": true, "info": true, "response_time": true},
+	"amqp":  {"message_count": true, "consumer_count": true, "response_time": true},
+	"ldap":  {"entry_count": true, "response_time": true},
+	"icmp":  {"packet_loss": true, "rtt_min": true, "rtt_avg": true, "rtt_max": true, "jitter": true, "response_time": true},
+}
+
+var assertionComparisons = map[string]bool{
+	"equals":       true,
+	"not_equals":   true,
+	"contains":     true,
+	"not_contains": true,
+	"matches":      true,
+	"not_matches":  true,
+	"less_than":    true,
+	"greater_than": true,
+	"in":           true,
+	"exists":       true,
+	"not_exists":   true,
+}
+
+// ParseAssertions returns the assertions stored as a JSON array on the service
+func (s *Service) ParseAssertions() ([]Assertion, error) {
+	if strings.TrimSpace(s.Assertions.String) == "" {
+		return nil, nil
+	}
+	var assertions []Assertion
+	if err := json.Unmarshal([]byte(s.Assertions.String), &assertions); err != nil {
+		return nil, errors.Wrap(err, "invalid assertions")
+	}
+	sources, ok := assertionSources[s.Type]
+	if !ok {
+		sources = assertionSources["http"]
+	}
+	for i, a := range assertions {
+		if !assertionComparisons[a.Comparison] {
+			return nil, fmt.Errorf("assertion %d has an unknown comparison '%s'", i+1, a.Comparison)
+		}
+		if !sources[a.Source] {
+			return nil, fmt.Errorf("assertion %d has an unknown source '%s'", i+1, a.Source)
+		}
+		if (a.Source == "header" || a.Source == "json" || a.Source == "keyspace" || a.Source == "info") && a.Property == "" {
+			return nil, fmt.Errorf("assertion %d on '%s' requires a property", i+1, a.Source)
+		}
+	}
+	return assertions, nil
+}
+
+// String returns a readable description of the assertion, used in failure issues
+func (a Assertion) String() string {
+	subject := a.Source
+	if a.Property != "" {
+		subject = fmt.Sprintf("%s '%s'", a.Source, a.Property)
+	}
+	if a.Comparison == "exists" || a.Comparison == "not_exists" {
+		return fmt.Sprintf("%s %s", subject, a.Comparison)
+	}
+	return fmt.Sprintf("%s %s '%s'", subject, a.Comparison, a.Value)
+}
+
+// actual returns the value from the response the assertion is checked against, and if it exists
+func (a Assertion) actual(res *assertionResponse) (string, bool) {
+	switch a.Source {
+	case "status_code":
+		return strconv.Itoa(res.StatusCode), true
+	case "header":
+		values, ok := res.Header[http.CanonicalHeaderKey(a.Property)]
+		return strings.Join(values, ","), ok
+	case "json":
+		result := gjson.GetBytes(res.Body, a.Property)
+		return result.String(), result.Exists()
+	case "body":
+		return string(res.Body), true
+	case "body_size":
+		return strconv.Itoa(len(res.Body)), true
+	case "response_time":
+		return strconv.FormatInt(res.Latency.Milliseconds(), 10), true
+	case "row_count":
+		return strconv.Itoa(res.Rows), true
+	case "value":
+		return res.Value, res.HasValue
+	case "role", "master_link_status", "connected_replicas", "used_memory_percent", "message_count", "consumer_count", "entry_count",
+		"packet_loss", "rtt_min", "rtt_avg", "rtt_max", "jitter":
+		value, ok := res.Info[a.Source]
+		return value, ok
+	case "info":
+		value, ok := res.Info[a.Property]
+		return value, ok
+	case "keyspace":
+		return redisKeyspace(res.Info, a.Property)
+	}
+	return "", false
+}
+
+// Check returns an error describing the failure if the response does not pass the assertion
+func (a Assertion) Check(res *assertionResponse) error {
+	actual, exists := a.actual(res)
+	var pass bool
+	switch a.Comparison {
+	case "exists":
+		pass = exists
+	case "not_exists":
+		pass = !exists
+	case "equals":
+		pass = exists && actual == a.Value
+	case "not_equals":
+		pass = actual != a.Value
+	case "contains":
+		pass = strings.Contains(actual, a.Value)
+	case "not_contains":
+		pass = !strings.Contains(actual, a.Value)
+	case "matches", "not_matches":
+		match, err := regexp.MatchString(a.Value, actual)
+		if err != nil {
+			return fmt.Errorf("%s: %v", a, err)
+		}
+		pass = match == (a.Comparison == "matches")
+	case "less_than", "greater_than":
+		got, err := strconv.ParseFloat(actual, 64)
+		if err != nil {
+			return fmt.Errorf("%s: value '%s' is not a number", a, actual)
+		}
+		want, err := strconv.ParseFloat(a.Value, 64)
+		if err != nil {
+			return fmt.Errorf("%s: '%s' is not a number", a, a.Value)
+		}
+		pass = (a.Comparison == "less_than" && got < want) || (a.Comparison == "greater_than" && got > want)
+	case "in":
+		pass = matchesSet(actual, a.Value)
+	}
+	if pass {
+		return nil
+	}
+	if a.Source == "body" {
+		return fmt.Errorf("%s failed", a)
+	}
+	return fmt.Errorf("%s failed, got '%s'", a, actual)
+}
+
+// matchesSet returns true if the value is in the comma delimited set. The set can contain
+// exact values, numeric ranges such as '200-299', and status classes such as '2xx'.
+func matchesSet(value, set string) bool {
+	for _, v := range strings.Split(set, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if strings.EqualFold(v, value) {
+			return true
+		}
+		lower := strings.ToLower(v)
+		if len(lower) == 3 && strings.HasSuffix(lower, "xx") && len(value) == 3 && value[0] == lower[0] {
+			return true
+		}
+		if parts := strings.SplitN(v, "-", 2); len(parts) == 2 {
+			num, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			from, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+			to, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+			if err1 == nil && err2 == nil && num >= from && num <= to {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasStatusAssertion returns true if an assertion checks the status code, which replaces the expected status code
+func hasStatusAssertion(assertions []Assertion) bool {
+	for _, a := range assertions {
+		if a.Source == "status_code" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAssertions returns the failure messages of every assertion the response did not pass,
+// failures of soft assertions are returned separately
+func checkAssertions(assertions []Assertion, res *assertionResponse) (failed []string, soft []string) {
+	for _, a := range assertions {
+		if err := a.Check(res); err != nil {
+			if a.Soft {
+				soft = append(soft, err.Error())
+			} else {
+				failed = append(failed, err.Error())
+			}
+		}
+	}
+	return failed, soft
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/statping-ng/statping-ng/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssertions(t *testing.T) {
+	res := &assertionResponse{
+		StatusCode: 204,
+		Header:     http.Header{"Content-Type": []string{"application/json"}, "X-Version": []string{"1.4.2"}},
+		Body:       []byte(`{"status":"ok","checks":{"db":{"latency":12}},"nodes":[{"up":true},{"up":false}]}`),
+		Latency:    350 * time.Millisecond,
+	}
+
+	tests := []struct {
+		Assertion Assertion
+		Pass      bool
+	}{
+		{Assertion{Source: "status_code", Comparison: "equals", Value: "204"}, true},
+		{Assertion{Source: "status_code", Comparison: "in", Value: "2xx"}, true},
+		{Assertion{Source: "status_code", Comparison: "in", Value: "200-203, 205"}, false},
+		{Assertion{Source: "status_code", Comparison: "in", Value: "200,204"}, true},
+		{Assertion{Source: "header", Property: "content-type", Comparison: "contains", Value: "json"}, true},
+		{Assertion{Source: "header", Property: "X-Version", Comparison: "matches", Value: `^1\.4\.`}, true},
+		{Assertion{Source: "header", Property: "X-Missing", Comparison: "not_exists"}, true},
+		{Assertion{Source: "header", Property: "X-Missing", Comparison: "equals", Value: ""}, false},
+		{Assertion{Source: "json", Property: "status", Comparison: "equals", Value: "ok"}, true},
+		{Assertion{Source: "json", Property: "status", Comparison: "not_equals", Value: "ok"}, false},
+		{Assertion{Source: "json", Property: "checks.db.latency", Comparison: "less_than", Value: "50"}, true},
+		{Assertion{Source: "json", Property: "nodes.#(up==false)#", Comparison: "equals", Value: `[{"up":false}]`}, true},
+		{Assertion{Source: "json", Property: "nodes.#", Comparison: "greater_than", Value: "2"}, false},
+		{Assertion{Source: "json", Property: "missing", Comparison: "exists"}, false},
+		{Assertion{Source: "body", Comparison: "not_contains", Value: "error"}, true},
+		{Assertion{Source: "body", Comparison: "not_contains", Value: "status"}, false},
+		{Assertion{Source: "body_size", Comparison: "less_than", Value: "1024"}, true},
+		{Assertion{Source: "response_time", Comparison: "less_than", Value: "300"}, false},
+		{Assertion{Source: "response_time", Comparison: "less_than", Value: "500"}, true},
+	}
+
+	for _, v := range tests {
+		t.Run(v.Assertion.String(), func(t *testing.T) {
+			err := v.Assertion.Check(res)
+			if v.Pass {
+				assert.Nil(t, err)
+			} else {
+				assert.NotNil(t, err)
+			}
+		})
+	}
+}
+
+func TestParseAssertions(t *testing.T) {
+	s := &Service{}
+	assertions, err := s.ParseAssertions()
+	require.Nil(t, err)
+	assert.Len(t, assertions, 0)
+
+	s.Assertions = null.NewNullString(`[{"source":"json","property":"status","comparison":"equals","value":"ok"}]`)
+	assertions, err = s.ParseAssertions()
+	require.Nil(t, err)
+	require.Len(t, assertions, 1)
+	assert.Equal(t, "json 'status' equals 'ok'", assertions[0].String())
+
+	s.Assertions = null.NewNullString(`[{"source":"json","comparison":"equals","value":"ok"}]`)
+	_, err = s.ParseAssertions()
+	assert.NotNil(t, err)
+
+	s.Assertions = null.NewNullString(`[{"source":"cookie","comparison":"equals"}]`)
+	_, err = s.ParseAssertions()
+	assert.NotNil(t, err)
+
+	s.Assertions = null.NewNullString(`[{"source":"body","comparison":"approximately"}]`)
+	_, err = s.ParseAssertions()
+	assert.NotNil(t, err)
+
+	s.Assertions = null.NewNullString(`{"source":"body"}`)
+	_, err = s.ParseAssertions()
+	assert.NotNil(t, err)
+}
+
+func TestHttpAssertions(t *testing.T) {
+	utils.InitEnvs()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"status":"degraded","version":"2.1.0"}`))
+	}))
+	defer server.Close()
+
+	s := &Service{
+		Name:           "HTTP Assertions",
+		Domain:         server.URL,
+		Type:           "http",
+		Method:         "GET",
+		ExpectedStatus: 200,
+		Timeout:        2,
+		Assertions:     null.NewNullString(`[{"source":"status_code","comparison":"in","value":"2xx"},{"source":"json","property":"version","comparison":"matches","value":"^2\\."}]`),
+	}
+	s.CheckService(false)
+	assert.True(t, s.Online)
+
+	s.Assertions = null.NewNullString(`[{"source":"status_code","comparison":"in","value":"2xx"},{"source":"json","property":"status","comparison":"equals","value":"ok"}]`)
+	_, err := CheckHttp(s, false)
+	require.NotNil(t, err)
+	assert.Equal(t, "1 of 2 assertions failed", err.Error())
+
+	failed, soft := checkAssertions([]Assertion{
+		{Source: "json", Property: "status", Comparison: "equals", Value: "ok"},
+		{Source: "body", Comparison: "not_contains", Value: "degraded"},
+		{Source: "body", Comparison: "contains", Value: "warnings", Soft: true},
+	}, &assertionResponse{Body: []byte(`{"status":"degraded"}`)})
+	assert.Equal(t, []string{
+		"json 'status' equals 'ok' failed, got 'degraded'",
+		"body not_contains 'degraded' failed",
+	}, failed)
+	assert.Equal(t, []string{"body contains 'warnings' failed"}, soft)
+}
+
+func TestIcmpAssertions(t *testing.T) {
+	stats := &utils.PingStats{Sent: 5, Received: 4, PacketLoss: 20, MinRtt: 10250, AvgRtt: 12000, MaxRtt: 15500, Jitter: 1800}
+	res := &assertionResponse{Latency: 12 * time.Millisecond, Info: icmpAssertionInfo(stats)}
+
+	s := &Service{Type: "icmp", Assertions: null.NewNullString(`[
+		{"source":"packet_loss","comparison":"less_than","value":"50"},
+		{"source":"packet_loss","comparison":"equals","value":"0","soft":true},
+		{"source":"rtt_min","comparison":"equals","value":"10.25"},
+		{"source":"rtt_max","comparison":"less_than","value":"20"},
+		{"source":"jitter","comparison":"greater_than","value":"1"},
+		{"source":"response_time","comparison":"less_than","value":"100"}
+	]`)}
+	assertions, err := s.ParseAssertions()
+	require.Nil(t, err)
+	failed, soft := checkAssertions(assertions, res)
+	assert.Empty(t, failed)
+	assert.Equal(t, []string{"packet_loss equals '0' failed, got '20'"}, soft)
+
+	s.Assertions = null.NewNullString(`[{"source":"row_count","comparison":"equals","value":"1"}]`)
+	_, err = s.ParseAssertions()
+	assert.NotNil(t, err)
+}
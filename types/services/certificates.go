@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/statping-ng/statping-ng/types/failures"
+	"github.com/statping-ng/statping-ng/utils"
+	"google.golang.org/grpc/credentials"
+)
+
+// Certificate contains the details of the TLS certificate chain a service presented on its last check
+type Certificate struct {
+	Subject       string    `json:"subject"`
+	Issuer        string    `json:"issuer"`
+	SANs          []string  `json:"sans"`
+	NotBefore     time.Time `json:"not_before"`
+	NotAfter      time.Time `json:"not_after"`
+	ChainNotAfter time.Time `json:"chain_not_after"`
+	HostnameValid bool      `json:"hostname_valid"`
+	DaysRemaining int       `json:"days_remaining"`
+}
+
+// parseCertificate returns the Certificate details for a TLS connection, the host is used to validate the hostname
+func parseCertificate(state *tls.ConnectionState, host string) *Certificate {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	leaf := state.PeerCertificates[0]
+
+	sans := append([]string{}, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	chainExpires := leaf.NotAfter
+	for _, c := range state.PeerCertificates[1:] {
+		if c.NotAfter.Before(chainExpires) {
+			chainExpires = c.NotAfter
+		}
+	}
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return &Certificate{
+		Subject:       leaf.Subject.String(),
+		Issuer:        leaf.Issuer.String(),
+		SANs:          sans,
+		NotBefore:     leaf.NotBefore,
+		NotAfter:      leaf.NotAfter,
+		ChainNotAfter: chainExpires,
+		HostnameValid: leaf.VerifyHostname(strings.Trim(host, "[]")) == nil,
+		DaysRemaining: int(chainExpires.Sub(utils.Now()).Hours() / 24),
+	}
+}
+
+// certificateLevel returns 'critical' or 'warning' if the certificate chain expires within
+// the thresholds of the service, otherwise it returns an empty string
+func (s *Service) certificateLevel() string {
+	if s.Certificate == nil {
+		return ""
+	}
+	days := s.Certificate.DaysRemaining
+	if s.CertCriticalDays > 0 && days <= s.CertCriticalDays {
+		return "critical"
+	}
+	if s.CertWarningDays > 0 && days <= s.CertWarningDays {
+		return "warning"
+	}
+	return ""
+}
+
+// recordCertificate will store the certificate for the service and send a warning notification
+// once the certificate reaches the warning or critical threshold. The service is not marked offline.
+func recordCertificate(s *Service, state *tls.ConnectionState, host string, record bool) {
+	cert := parseCertificate(state, host)
+	if cert == nil {
+		return
+	}
+	s.Certificate = cert
+	if !record {
+		return
+	}
+
+	level := s.certificateLevel()
+	switch {
+	case level == "":
+		s.certLevel = ""
+		return
+	case level == s.certLevel, level == "warning" && s.certLevel == "critical":
+		return
+	}
+	s.certLevel = level
+
+	issue := fmt.Sprintf("Certificate for %v expires in %d days on %v (%s)", host, cert.DaysRemaining, cert.ChainNotAfter.Format(time.RFC1123), level)
+	log.WithFields(utils.ToFields(cert, s)).Warnln(fmt.Sprintf("Service %v %v", s.Name, issue))
+	sendWarning(s, &failures.Failure{
+		Service:   s.Id,
+		Issue:     issue,
+		Reason:    "certificate_expiring",
+		CreatedAt: utils.Now(),
+	})
+}
+
+// unverifiedState returns the TLS state with the certificates the server presented if the handshake failed
+// on verifying them, such as an expired or self signed certificate
+func unverifiedState(err error) *tls.ConnectionState {
+	var verifyErr *tls.CertificateVerificationError
+	if !errors.As(err, &verifyErr) || len(verifyErr.UnverifiedCertificates) == 0 {
+		return nil
+	}
+	return &tls.ConnectionState{PeerCertificates: verifyErr.UnverifiedCertificates}
+}
+
+// recordFailedCertificate will store the certificate of a handshake that failed on verification. No warning
+// is sent, the failure of the check already notifies that the service is offline.
+func recordFailedCertificate(s *Service, err error, host string) {
+	if state := unverifiedState(err); state != nil {
+		recordCertificate(s, state, host, false)
+	}
+}
+
+// certificateCapture wraps gRPC transport credentials to keep the TLS state of the last handshake
+type certificateCapture struct {
+	credentials.TransportCredentials
+	last *capturedState
+}
+
+type capturedState struct {
+	sync.Mutex
+	state *tls.ConnectionState
+}
+
+func newCertificateCapture(creds credentials.TransportCredentials) *certificateCapture {
+	return &certificateCapture{TransportCredentials: creds, last: &capturedState{}}
+}
+
+func (c *certificateCapture) ClientHandshake(ctx context.Context, authority string, rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	conn, info, err := c.TransportCredentials.ClientHandshake(ctx, authority, rawConn)
+	state := unverifiedState(err)
+	if tlsInfo, ok := info.(credentials.TLSInfo); ok {
+		state = &tlsInfo.State
+	}
+	if state != nil {
+		c.last.Lock()
+		c.last.state = state
+		c.last.Unlock()
+	}
+	return conn, info, err
+}
+
+// Clone keeps the captured state shared, gRPC clones the credentials when dialing
+func (c *certificateCapture) Clone() credentials.TransportCredentials {
+	return &certificateCapture{TransportCredentials: c.TransportCredentials.Clone(), last: c.last}
+}
+
+// State returns the TLS connection state of the last handshake, the state of a handshake that failed
+// on verification only contains the certificates of the server
+func (c *certificateCapture) State() *tls.ConnectionState {
+	c.last.Lock()
+	defer c.last.Unlock()
+	return c.last.state
+}
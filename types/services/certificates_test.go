@@ -0,0 +1,122 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/statping-ng/statping-ng/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpCertificate(t *testing.T) {
+	utils.InitEnvs()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	s := &Service{
+		Name:           "TLS Certificate",
+		Domain:         server.URL,
+		Type:           "http",
+		Method:         "GET",
+		ExpectedStatus: 200,
+		Timeout:        2,
+		VerifySSL:      null.NewNullBool(false),
+	}
+	s.CheckService(false)
+	require.True(t, s.Online)
+	require.NotNil(t, s.Certificate)
+
+	cert := server.Certificate()
+	assert.Equal(t, cert.NotAfter, s.Certificate.NotAfter)
+	assert.Equal(t, cert.Issuer.String(), s.Certificate.Issuer)
+	assert.Contains(t, s.Certificate.SANs, "example.com")
+	assert.Contains(t, s.Certificate.SANs, "127.0.0.1")
+	assert.True(t, s.Certificate.HostnameValid)
+	assert.Greater(t, s.Certificate.DaysRemaining, 0)
+}
+
+// expiredCertificate returns a self signed certificate for 127.0.0.1 that expired 3 days ago
+func expiredCertificate(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "expired.test"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             utils.Now().Add(-30 * 24 * time.Hour),
+		NotAfter:              utils.Now().Add(-3 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.Nil(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestExpiredCertificate(t *testing.T) {
+	utils.InitEnvs()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{expiredCertificate(t)}}
+	server.StartTLS()
+	defer server.Close()
+
+	for _, serviceType := range []string{"http", "tcp"} {
+		t.Run(serviceType, func(t *testing.T) {
+			s := &Service{
+				Name:           "Expired Certificate",
+				Domain:         server.URL,
+				Type:           serviceType,
+				Method:         "GET",
+				ExpectedStatus: 200,
+				Timeout:        2,
+				VerifySSL:      null.NewNullBool(true),
+			}
+			if serviceType == "tcp" {
+				s.Domain = "127.0.0.1"
+				s.Port = server.Listener.Addr().(*net.TCPAddr).Port
+				// a TLS certificate makes the TCP check connect with TLS
+				s.TLSCert = null.NewNullString("expired")
+			}
+			s.CheckService(false)
+			assert.False(t, s.Online)
+			require.NotNil(t, s.Certificate)
+			assert.Equal(t, "CN=expired.test", s.Certificate.Subject)
+			assert.Less(t, s.Certificate.DaysRemaining, 0)
+		})
+	}
+}
+
+func TestCertificateLevel(t *testing.T) {
+	s := &Service{CertWarningDays: 30, CertCriticalDays: 7}
+	assert.Equal(t, "", s.certificateLevel())
+
+	s.Certificate = &Certificate{DaysRemaining: 90}
+	assert.Equal(t, "", s.certificateLevel())
+
+	s.Certificate.DaysRemaining = 30
+	assert.Equal(t, "warning", s.certificateLevel())
+
+	s.Certificate.DaysRemaining = 3
+	assert.Equal(t, "critical", s.certificateLevel())
+
+	s.CertCriticalDays = 0
+	assert.Equal(t, "warning", s.certificateLevel())
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/statping-ng/statping-ng/types/changes"
+	"github.com/statping-ng/statping-ng/types/failures"
+	"github.com/statping-ng/statping-ng/utils"
+)
+
+func (s *Service) ChangesColumnID() (string, int64) {
+	return "service", s.Id
+}
+
+// AllChanges returns the recorded versions of the watched content of the service
+func (s *Service) AllChanges() changes.Changer {
+	return changes.AllChanges(s)
+}
+
+// watchedContent returns the part of the body that is watched for changes: the text of the elements matching
+// the CSS selector, the matches of the extraction regex, or the first group of each match if the regex has one,
+// and finally the body without the volatile parts matching the ignore regexes, one regex on each line.
+func (s *Service) watchedContent(body []byte) (string, error) {
+	content := string(body)
+	if selector := strings.TrimSpace(s.ContentSelector.String); selector != "" {
+		text, err := utils.SelectText(body, selector)
+		if err != nil {
+			return "", err
+		}
+		content = text
+	}
+	if s.ContentRegex.String != "" {
+		re, err := regexp.Compile(s.ContentRegex.String)
+		if err != nil {
+			return "", err
+		}
+		matches := re.FindAllStringSubmatch(content, -1)
+		if len(matches) == 0 {
+			return "", fmt.Errorf("content regex '%s' did not match", s.ContentRegex.String)
+		}
+		var parts []string
+		for _, m := range matches {
+			if len(m) > 1 {
+				parts = append(parts, m[1])
+			} else {
+				parts = append(parts, m[0])
+			}
+		}
+		content = strings.Join(parts, "\n")
+	}
+	for _, pattern := range strings.Split(s.ContentIgnore.String, "\n") {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return "", err
+		}
+		content = re.ReplaceAllString(content, "")
+	}
+	return content, nil
+}
+
+// contentHash returns the SHA-256 hash of the content as hex
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// contentDiff returns the unified diff between two versions of the content and the amount of lines added and removed
+func contentDiff(previous, current *changes.Change) (string, int, int) {
+	a, b := difflib.SplitLines(previous.Content), difflib.SplitLines(current.Content)
+	var added, removed int
+	for _, op := range difflib.NewMatcher(a, b).GetOpCodes() {
+		switch op.Tag {
+		case 'r':
+			removed += op.I2 - op.I1
+			added += op.J2 - op.J1
+		case 'd':
+			removed += op.I2 - op.I1
+		case 'i':
+			added += op.J2 - op.J1
+		}
+	}
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        a,
+		B:        b,
+		FromFile: "previous",
+		FromDate: previous.CreatedAt.Format(time.RFC3339),
+		ToFile:   "current",
+		ToDate:   current.CreatedAt.Format(time.RFC3339),
+		Context:  3,
+	})
+	return diff, added, removed
+}
+
+// checkContent hashes the watched content of the body and records a Change with the diff against the previous
+// version when the hash is different. A warning notification is sent for each change after the first version.
+func (s *Service) checkContent(body []byte, record bool) error {
+	content, err := s.watchedContent(body)
+	if err != nil {
+		return err
+	}
+	hash := contentHash(content)
+	s.ContentHash = hash
+	if !record {
+		return nil
+	}
+
+	if s.lastChange == nil {
+		s.lastChange = s.AllChanges().Last()
+	}
+	previous := s.lastChange
+	if previous != nil && previous.Hash == hash {
+		return nil
+	}
+
+	change := &changes.Change{
+		Service:   s.Id,
+		Hash:      hash,
+		Content:   content,
+		CreatedAt: utils.Now(),
+	}
+	if previous != nil {
+		change.Diff, change.Added, change.Removed = contentDiff(previous, change)
+	}
+	if err := change.Create(); err != nil {
+		log.Error(err)
+	}
+	s.lastChange = change
+	if previous == nil {
+		return nil
+	}
+
+	issue := fmt.Sprintf("Content changed, %d lines added and %d lines removed", change.Added, change.Removed)
+	log.WithFields(utils.ToFields(s)).Warnln(fmt.Sprintf("Service %v %v", s.Name, issue))
+	sendWarning(s, &failures.Failure{
+		Service:   s.Id,
+		Issue:     issue,
+		Reason:    "content_changed",
+		CreatedAt: utils.Now(),
+	})
+	return nil
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/statping-ng/statping-ng/database"
+	"github.com/statping-ng/statping-ng/types/changes"
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/statping-ng/statping-ng/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const contentPage = `<html>
+<head><title>Vendor Status</title><script>var rendered = "12:00:01";</script></head>
+<body>
+  <div id="header"><a href="/">Vendor</a></div>
+  <div id="status" class="components">
+    <div class="component" data-name="api"><span class="name">API</span> <span class="state">Operational</span></div>
+    <div class="component" data-name="web"><span class="name">Website</span> <span class="state">Degraded   Performance</span></div>
+    <div class="component" data-name="cdn,edge"><span class="name">CDN</span></div>
+  </div>
+  <p class="footer">Rendered at 2020-01-01 12:00:01 by node-7</p>
+</body>
+</html>`
+
+func TestWatchedContent(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Selector string
+		Regex    string
+		Ignore   string
+		Expected string
+		Error    bool
+	}{
+		{"Body", "", "", "", contentPage, false},
+		{"Selector", "#status .state", "", "", "Operational\nDegraded Performance", false},
+		{"Child Selector", "div#status > .component[data-name=web] span", "", "", "Website\nDegraded Performance", false},
+		{"Several Selectors", "#header, p.footer", "", "", "Vendor\nRendered at 2020-01-01 12:00:01 by node-7", false},
+		{"Comma In Attribute", `[data-name="cdn,edge"] .name, #header`, "", "", "Vendor\nCDN", false},
+		{"Skips Scripts", "head", "", "", "Vendor Status", false},
+		{"Selector Without Match", ".incidents", "", "", "", true},
+		{"Invalid Selector", "div >", "", "", "", true},
+		{"Regex", "", `<span class="state">[^<]+</span>`, "", `<span class="state">Operational</span>` + "\n" + `<span class="state">Degraded   Performance</span>`, false},
+		{"Regex Group", "", `data-name="(\w+)"`, "", "api\nweb", false},
+		{"Regex Without Match", "", `class="incident"`, "", "", true},
+		{"Selector And Ignore", "p.footer", "", `\d{4}-\d{2}-\d{2} [\d:]+` + "\n" + `node-\d+`, "Rendered at  by ", false},
+		{"Invalid Ignore", "p.footer", "", `[`, "", true},
+	}
+
+	for _, v := range tests {
+		t.Run(v.Name, func(t *testing.T) {
+			s := &Service{
+				ContentSelector: null.NewNullString(v.Selector),
+				ContentRegex:    null.NewNullString(v.Regex),
+				ContentIgnore:   null.NewNullString(v.Ignore),
+			}
+			content, err := s.watchedContent([]byte(contentPage))
+			if v.Error {
+				assert.Error(t, err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, v.Expected, content)
+		})
+	}
+}
+
+func TestCheckContent(t *testing.T) {
+	require.Nil(t, utils.InitLogs())
+	db, err := database.OpenTester()
+	require.Nil(t, err)
+	db.AutoMigrate(&changes.Change{})
+	changes.SetDB(db)
+
+	state, node := "Operational", 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<div id="status"><span>API</span><span>%s</span></div><p>Served by node-%d</p>`, state, node)
+	}))
+	defer server.Close()
+
+	s := &Service{
+		Id:              7,
+		Name:            "Vendor Status",
+		Domain:          server.URL,
+		Type:            "http",
+		Method:          "GET",
+		ExpectedStatus:  200,
+		Timeout:         2,
+		ContentCheck:    null.NewNullBool(true),
+		ContentSelector: null.NewNullString("#status, p"),
+		ContentIgnore:   null.NewNullString(`node-\d+`),
+	}
+	check := func() {
+		_, err := CheckHttp(s, false)
+		require.Nil(t, err)
+		require.Nil(t, s.checkContent([]byte(s.LastResponse), true))
+	}
+
+	check()
+	first := s.ContentHash
+	list := s.AllChanges().List()
+	require.Len(t, list, 1)
+	assert.Equal(t, first, list[0].Hash)
+	assert.Equal(t, "API\nOperational\nServed by ", list[0].Content)
+	assert.Empty(t, list[0].Diff)
+
+	// volatile parts are ignored
+	node = 2
+	check()
+	assert.Equal(t, first, s.ContentHash)
+	assert.Equal(t, 1, s.AllChanges().Count())
+
+	state = "Major Outage"
+	check()
+	assert.NotEqual(t, first, s.ContentHash)
+	list = s.AllChanges().List()
+	require.Len(t, list, 2)
+	assert.Equal(t, 1, list[0].Added)
+	assert.Equal(t, 1, list[0].Removed)
+	assert.Contains(t, list[0].Diff, "--- previous")
+	assert.Contains(t, list[0].Diff, "-Operational\n+Major Outage\n")
+
+	// the previous version is loaded from the database after a restart
+	restarted := &Service{Id: s.Id, ContentSelector: s.ContentSelector, ContentIgnore: s.ContentIgnore}
+	require.Nil(t, restarted.checkContent([]byte(s.LastResponse), true))
+	assert.Equal(t, 2, s.AllChanges().Count())
+
+	t.Run("Content Error Fails Check", func(t *testing.T) {
+		s.ContentSelector = null.NewNullString(".missing")
+		_, err := CheckHttp(s, false)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"strings"
+)
+
+// headerCredentials returns the 'Username' and 'Password' from the comma delimited headers of the service.
+// Values can contain commas, such as the DN of a LDAP bind.
+func (s *Service) headerCredentials() (username, password string) {
+	if !s.Headers.Valid {
+		return "", ""
+	}
+	var value *string
+	for _, header := range strings.Split(s.Headers.String, ",") {
+		keyVal := strings.SplitN(header, "=", 2)
+		key := strings.ToLower(strings.TrimSpace(keyVal[0]))
+		switch {
+		case len(keyVal) == 2 && key == "username":
+			username, value = keyVal[1], &username
+		case len(keyVal) == 2 && key == "password":
+			password, value = keyVal[1], &password
+		case value != nil:
+			*value += "," + header
+		}
+	}
+	return username, password
+}
@@ -110,8 +110,7 @@ func (s *Service) Update() error {
 	q := db.Update(s)
 	s.Close()
 	allServices[s.Id] = s
-	s.SleepDuration = s.Duration()
-	go ServiceCheckQueue(allServices[s.Id], true)
+	ServiceCheckQueue(allServices[s.Id], true)
 	return q.Error()
 }
 
@@ -123,6 +122,9 @@ func (s *Service) Delete() error {
 	if err := s.AllHits().DeleteAll(); err != nil {
 		return err
 	}
+	if err := s.AllChanges().DeleteAll(); err != nil {
+		return err
+	}
 	if err := s.DeleteCheckins(); err != nil {
 		return err
 	}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Status returns 'online', 'degraded' or 'offline' for the service
+func (s *Service) Status() string {
+	switch {
+	case !s.Online:
+		return "offline"
+	case s.Degraded:
+		return "degraded"
+	default:
+		return "online"
+	}
+}
+
+// updateDegraded will mark an online service as degraded if the latency of the last check reached
+// the degraded latency threshold, or if any soft assertions failed
+func (s *Service) updateDegraded() {
+	var issues []string
+	if s.Online {
+		if s.DegradedLatency > 0 && s.Latency >= int64(s.DegradedLatency)*1000 {
+			issues = append(issues, fmt.Sprintf("Latency %s reached the degraded threshold of %dms", humanMicro(s.Latency), s.DegradedLatency))
+		}
+		issues = append(issues, s.softFailures...)
+	}
+	s.Degraded = len(issues) > 0
+	s.DegradedIssue = strings.Join(issues, "; ")
+}
+
+// onlineGauge returns the value for the 'online' Prometheus gauge, 1 if online, 0.5 if degraded and 0 if offline
+func (s *Service) onlineGauge() float64 {
+	switch s.Status() {
+	case "online":
+		return 1.
+	case "degraded":
+		return 0.5
+	default:
+		return 0.
+	}
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/statping-ng/statping-ng/types/failures"
+	"github.com/statping-ng/statping-ng/types/hits"
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/statping-ng/statping-ng/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceStatus(t *testing.T) {
+	s := &Service{Online: true, DegradedLatency: 200, Latency: 150000}
+	s.updateDegraded()
+	assert.Equal(t, "online", s.Status())
+	assert.Equal(t, 1., s.onlineGauge())
+
+	s.Latency = 250000
+	s.updateDegraded()
+	assert.Equal(t, "degraded", s.Status())
+	assert.Equal(t, 0.5, s.onlineGauge())
+	assert.Contains(t, s.DegradedIssue, "degraded threshold of 200ms")
+
+	s.Latency = 1000
+	s.softFailures = []string{"json 'version' equals '2' failed, got '1'"}
+	s.updateDegraded()
+	assert.Equal(t, "degraded", s.Status())
+	assert.Equal(t, "json 'version' equals '2' failed, got '1'", s.DegradedIssue)
+
+	s.Online = false
+	s.updateDegraded()
+	assert.Equal(t, "offline", s.Status())
+	assert.Equal(t, 0., s.onlineGauge())
+	assert.Empty(t, s.DegradedIssue)
+}
+
+func TestHttpSoftAssertions(t *testing.T) {
+	utils.InitEnvs()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok","queue":950}`))
+	}))
+	defer server.Close()
+
+	s := &Service{
+		Name:           "HTTP Soft Assertions",
+		Domain:         server.URL,
+		Type:           "http",
+		Method:         "GET",
+		ExpectedStatus: 200,
+		Timeout:        2,
+		Assertions:     null.NewNullString(`[{"source":"json","property":"status","comparison":"equals","value":"ok"},{"source":"json","property":"queue","comparison":"less_than","value":"500","soft":true}]`),
+	}
+	s.CheckService(false)
+	assert.True(t, s.Online)
+	assert.True(t, s.Degraded)
+	assert.Equal(t, "json 'queue' less_than '500' failed, got '950'", s.DegradedIssue)
+
+	s.Assertions = null.NewNullString(`[{"source":"json","property":"queue","comparison":"less_than","value":"1000","soft":true}]`)
+	s.CheckService(false)
+	assert.True(t, s.Online)
+	assert.False(t, s.Degraded)
+}
+
+func TestUptimeDataDegraded(t *testing.T) {
+	now := utils.Now()
+	hitsList := []*hits.Hit{
+		{CreatedAt: now.Add(-60 * time.Minute)},
+		{CreatedAt: now.Add(-50 * time.Minute), Degraded: true},
+		{CreatedAt: now.Add(-40 * time.Minute), Degraded: true},
+		{CreatedAt: now.Add(-20 * time.Minute)},
+	}
+	fails := []*failures.Failure{
+		{CreatedAt: now.Add(-30 * time.Minute)},
+	}
+
+	s := Service{Online: true}
+	data, err := s.UptimeData(hitsList, fails)
+	require.Nil(t, err)
+
+	assert.Equal(t, (20 * time.Minute).Milliseconds(), data.Degraded)
+	assert.Equal(t, (10 * time.Minute).Milliseconds(), data.Downtime)
+	require.True(t, len(data.Series) >= 3)
+	assert.True(t, data.Series[1].Online)
+	assert.True(t, data.Series[1].Degraded)
+	assert.False(t, data.Series[2].Online)
+
+	data, err = s.UptimeData(hitsList[:1], nil)
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), data.Degraded)
+}
+
+func TestUptimeDataAllDegraded(t *testing.T) {
+	now := utils.Now()
+	hitsList := []*hits.Hit{
+		{CreatedAt: now.Add(-30 * time.Minute), Degraded: true},
+		{CreatedAt: now.Add(-20 * time.Minute), Degraded: true},
+		{CreatedAt: now.Add(-10 * time.Minute), Degraded: true},
+	}
+
+	s := Service{Online: true, Degraded: true}
+	data, err := s.UptimeData(hitsList, nil)
+	require.Nil(t, err)
+
+	require.Len(t, data.Series, 1)
+	assert.True(t, data.Series[0].Online)
+	assert.True(t, data.Series[0].Degraded)
+	assert.InDelta(t, (30 * time.Minute).Milliseconds(), data.Degraded, 1000)
+	assert.Equal(t, int64(0), data.Uptime)
+	assert.Equal(t, int64(0), data.Downtime)
+}
+
+func TestUptimeDataDownDegradedOnline(t *testing.T) {
+	now := utils.Now()
+	hitsList := []*hits.Hit{
+		{CreatedAt: now.Add(-40 * time.Minute), Degraded: true},
+		{CreatedAt: now.Add(-30 * time.Minute), Degraded: true},
+		{CreatedAt: now.Add(-20 * time.Minute)},
+	}
+	fails := []*failures.Failure{
+		{CreatedAt: now.Add(-60 * time.Minute)},
+		{CreatedAt: now.Add(-50 * time.Minute)},
+	}
+
+	s := Service{Online: true}
+	data, err := s.UptimeData(hitsList, fails)
+	require.Nil(t, err)
+
+	assert.Equal(t, (20 * time.Minute).Milliseconds(), data.Downtime)
+	assert.Equal(t, (20 * time.Minute).Milliseconds(), data.Degraded)
+	assert.InDelta(t, (20 * time.Minute).Milliseconds(), data.Uptime, 1000)
+	assert.InDelta(t, utils.Now().Sub(now.Add(-60*time.Minute)).Milliseconds(), data.Uptime+data.Degraded+data.Downtime, 1000)
+	require.Len(t, data.Series, 3)
+	assert.Equal(t, data.Series[1].End, data.Series[2].Start)
+}
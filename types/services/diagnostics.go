@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/statping-ng/statping-ng/types/failures"
+	"github.com/statping-ng/statping-ng/utils"
+)
+
+const (
+	// diagnosticTimeout is the time to wait for the DNS answers and for each traceroute probe
+	diagnosticTimeout = 2 * time.Second
+	diagnosticMaxHops = 30
+)
+
+// diagnosticTypes are the service types that can collect network diagnostics when they go offline
+var diagnosticTypes = map[string]bool{"http": true, "tcp": true, "icmp": true}
+
+// diagnosticTarget returns the host and port of the service, the port is 0 for ICMP services
+func (s *Service) diagnosticTarget() (string, int) {
+	if s.Type != "http" {
+		return s.Domain, s.Port
+	}
+	u, err := url.Parse(s.Domain)
+	if err != nil {
+		return s.Domain, s.Port
+	}
+	port, _ := strconv.Atoi(u.Port())
+	if port == 0 {
+		port = 80
+		if u.Scheme == "https" {
+			port = 443
+		}
+	}
+	return u.Hostname(), port
+}
+
+// collectDiagnostics resolves the host of the service with the system resolver, finds the local source
+// address used to reach it and traces the hops to the first resolved IP address.
+func (s *Service) collectDiagnostics() *failures.Diagnostics {
+	host, port := s.diagnosticTarget()
+	d := &failures.Diagnostics{Host: host}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), diagnosticTimeout)
+		defer cancel()
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			d.DnsError = err.Error()
+			return d
+		}
+		for _, addr := range addrs {
+			d.DnsAnswers = append(d.DnsAnswers, addr.IP.String())
+		}
+		ip = addrs[0].IP
+	}
+	d.ResolvedIP = ip.String()
+
+	// connecting a UDP socket selects the route and source address without sending anything
+	if port == 0 {
+		port = 9
+	}
+	if conn, err := net.Dial("udp", net.JoinHostPort(d.ResolvedIP, strconv.Itoa(port))); err == nil {
+		d.SourceAddress = conn.LocalAddr().(*net.UDPAddr).IP.String()
+		conn.Close()
+	}
+
+	hops, err := utils.Traceroute(d.ResolvedIP, diagnosticMaxHops, diagnosticTimeout)
+	if err != nil {
+		d.TraceError = err.Error()
+		return d
+	}
+	for _, hop := range hops {
+		d.Hops = append(d.Hops, failures.Hop(hop))
+	}
+	return d
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/statping-ng/statping-ng/types/failures"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnosticTarget(t *testing.T) {
+	tests := []struct {
+		Service *Service
+		Host    string
+		Port    int
+	}{
+		{&Service{Type: "http", Domain: "https://statping.example.com/health"}, "statping.example.com", 443},
+		{&Service{Type: "http", Domain: "http://statping.example.com:8080"}, "statping.example.com", 8080},
+		{&Service{Type: "http", Domain: "http://[::1]/"}, "::1", 80},
+		{&Service{Type: "tcp", Domain: "db.example.com", Port: 5432}, "db.example.com", 5432},
+		{&Service{Type: "icmp", Domain: "10.0.0.1"}, "10.0.0.1", 0},
+	}
+	for _, v := range tests {
+		t.Run(v.Service.Domain, func(t *testing.T) {
+			host, port := v.Service.diagnosticTarget()
+			assert.Equal(t, v.Host, host)
+			assert.Equal(t, v.Port, port)
+		})
+	}
+}
+
+func TestCollectDiagnostics(t *testing.T) {
+	s := &Service{Type: "tcp", Domain: "localhost", Port: 1}
+	d := s.collectDiagnostics()
+	assert.Equal(t, "localhost", d.Host)
+	assert.Contains(t, d.DnsAnswers, "127.0.0.1")
+	assert.NotEmpty(t, d.ResolvedIP)
+	assert.NotEmpty(t, d.SourceAddress)
+
+	s = &Service{Type: "icmp", Domain: "127.0.0.1"}
+	d = s.collectDiagnostics()
+	assert.Empty(t, d.DnsAnswers)
+	assert.Equal(t, "127.0.0.1", d.ResolvedIP)
+	assert.Equal(t, "127.0.0.1", d.SourceAddress)
+	require.Empty(t, d.TraceError)
+	require.Len(t, d.Hops, 1)
+	assert.Equal(t, []failures.Hop{{TTL: 1, Address: "127.0.0.1", Latency: d.Hops[0].Latency, Reached: true}}, d.Hops)
+
+	s = &Service{Type: "http", Domain: "http://statping.invalid"}
+	d = s.collectDiagnostics()
+	assert.NotEmpty(t, d.DnsError)
+	assert.Empty(t, d.ResolvedIP)
+}
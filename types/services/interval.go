@@ -0,0 +1,37 @@
+package services
+
+import "time"
+
+// checkInterval returns the time until the next check after a check finished at 'now'. An offline service
+// is checked every 'down_interval' seconds, doubled after each failed check up to 'down_interval_max' seconds.
+// After the service recovered it is checked every 'recovery_interval' seconds for one normal interval.
+func (s *Service) checkInterval(now time.Time) time.Duration {
+	interval := s.Duration()
+	if !s.Online {
+		s.downChecks++
+		s.recoveredAt = time.Time{}
+		if s.DownInterval <= 0 {
+			return interval
+		}
+		down := time.Duration(s.DownInterval) * time.Second
+		if s.DownIntervalMax > s.DownInterval {
+			max := time.Duration(s.DownIntervalMax) * time.Second
+			for i := 1; i < s.downChecks && down < max; i++ {
+				down *= 2
+			}
+			if down > max {
+				down = max
+			}
+		}
+		return down
+	}
+
+	if s.downChecks > 0 {
+		s.downChecks = 0
+		s.recoveredAt = now
+	}
+	if s.RecoveryInterval > 0 && !s.recoveredAt.IsZero() && now.Sub(s.recoveredAt) < interval {
+		return time.Duration(s.RecoveryInterval) * time.Second
+	}
+	return interval
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckInterval(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Default Intervals", func(t *testing.T) {
+		s := &Service{Interval: 60}
+		assert.Equal(t, time.Minute, s.checkInterval(now))
+		s.Online = false
+		assert.Equal(t, time.Minute, s.checkInterval(now))
+		s.Online = true
+		assert.Equal(t, time.Minute, s.checkInterval(now))
+	})
+
+	t.Run("Down Interval With Backoff", func(t *testing.T) {
+		s := &Service{Interval: 60, DownInterval: 5, DownIntervalMax: 30}
+		var intervals []time.Duration
+		for i := 0; i < 5; i++ {
+			intervals = append(intervals, s.checkInterval(now))
+		}
+		assert.Equal(t, []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second, 30 * time.Second, 30 * time.Second}, intervals)
+	})
+
+	t.Run("Down Interval Without Backoff", func(t *testing.T) {
+		s := &Service{Interval: 60, DownInterval: 5}
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, 5*time.Second, s.checkInterval(now))
+		}
+	})
+
+	t.Run("Recovery Interval", func(t *testing.T) {
+		s := &Service{Interval: 60, DownInterval: 5, DownIntervalMax: 30, RecoveryInterval: 2}
+		s.checkInterval(now)
+		s.checkInterval(now)
+
+		s.Online = true
+		recovered := now.Add(15 * time.Second)
+		assert.Equal(t, 2*time.Second, s.checkInterval(recovered))
+		assert.Equal(t, 2*time.Second, s.checkInterval(recovered.Add(59*time.Second)))
+		assert.Equal(t, time.Minute, s.checkInterval(recovered.Add(time.Minute)))
+
+		// the backoff starts again after the service recovered
+		s.Online = false
+		assert.Equal(t, 5*time.Second, s.checkInterval(recovered.Add(2*time.Minute)))
+	})
+}
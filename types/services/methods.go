@@ -84,14 +84,21 @@ func (s Service) Duration() time.Duration {
 	return time.Duration(s.Interval) * time.Second
 }
 
-// Start will create a channel for the service checking go routine
+// UptimeData returns the series of online, degraded and offline periods from the hits and failures of the service
 func (s Service) UptimeData(hits []*hits.Hit, fails []*failures.Failure) (*UptimeSeries, error) {
 	if len(hits) == 0 {
 		return nil, errors.New("service does not have any successful hits")
 	}
-	// if theres no failures, then its been online 100%,
+	degraded := false
+	for _, v := range hits {
+		if v.Degraded {
+			degraded = true
+			break
+		}
+	}
+	// if theres no failures or degraded hits, then its been online 100%,
 	// return a series from created time, to current.
-	if len(fails) == 0 {
+	if len(fails) == 0 && !degraded {
 		fistHit := hits[0]
 		duration := utils.Now().Sub(fistHit.CreatedAt).Milliseconds()
 		set := []series{
@@ -112,22 +119,18 @@ func (s Service) UptimeData(hits []*hits.Hit, fails []*failures.Failure) (*Uptim
 		return out, nil
 	}
 
-	tMap := make(map[time.Time]bool)
+	tMap := make(map[time.Time]ser)
 
 	for _, v := range hits {
-		tMap[v.CreatedAt] = true
+		tMap[v.CreatedAt] = ser{Time: v.CreatedAt, Online: true, Degraded: v.Degraded}
 	}
 	for _, v := range fails {
-		tMap[v.CreatedAt] = false
+		tMap[v.CreatedAt] = ser{Time: v.CreatedAt, Online: false}
 	}
 
 	var servs []ser
-	for t, v := range tMap {
-		s := ser{
-			Time:   t,
-			Online: v,
-		}
-		servs = append(servs, s)
+	for _, v := range tMap {
+		servs = append(servs, v)
 	}
 	if len(servs) == 0 {
 		return nil, errors.New("error generating uptime data structure")
@@ -135,62 +138,74 @@ func (s Service) UptimeData(hits []*hits.Hit, fails []*failures.Failure) (*Uptim
 	sort.Sort(ByTime(servs))
 
 	var allTimes []series
-	online := servs[0].Online
+	current := servs[0]
 	thisTime := servs[0].Time
 	for i := 0; i < len(servs); i++ {
 		v := servs[i]
-		if v.Online != online {
+		if v.Online != current.Online || v.Degraded != current.Degraded {
 			s := series{
 				Start:    thisTime,
 				End:      v.Time,
 				Duration: v.Time.Sub(thisTime).Milliseconds(),
-				Online:   online,
+				Online:   current.Online,
+				Degraded: current.Degraded,
 			}
 			allTimes = append(allTimes, s)
 			thisTime = v.Time
-			online = v.Online
+			current = v
 		}
 	}
-	if len(allTimes) == 0 {
-		return nil, errors.New("error generating uptime series structure")
-	}
 
 	first := servs[0].Time
-	last := servs[len(servs)-1].Time
-	if !s.Online {
-		s := series{
-			Start:    allTimes[len(allTimes)-1].End,
-			End:      utils.Now(),
-			Duration: utils.Now().Sub(last).Milliseconds(),
-			Online:   s.Online,
+	// no transitions, the whole period was in a single state
+	if len(allTimes) == 0 {
+		set := []series{
+			{
+				Start:    first,
+				End:      utils.Now(),
+				Duration: utils.Now().Sub(first).Milliseconds(),
+				Online:   current.Online,
+				Degraded: current.Degraded,
+			},
 		}
-		allTimes = append(allTimes, s)
-	} else {
-		l := allTimes[len(allTimes)-1]
-		s := series{
-			Start:    l.Start,
+		out := &UptimeSeries{
+			Start:    first,
 			End:      utils.Now(),
-			Duration: utils.Now().Sub(l.Start).Milliseconds(),
-			Online:   true,
+			Uptime:   addDurations(set, true, false),
+			Degraded: addDurations(set, true, true),
+			Downtime: addDurations(set, false, false),
+			Series:   set,
 		}
-		allTimes = append(allTimes, s)
+		return out, nil
 	}
 
+	last := servs[len(servs)-1].Time
+	// the state of the last sample lasts from the last transition until now
+	l := allTimes[len(allTimes)-1]
+	allTimes = append(allTimes, series{
+		Start:    l.End,
+		End:      utils.Now(),
+		Duration: utils.Now().Sub(l.End).Milliseconds(),
+		Online:   current.Online,
+		Degraded: current.Degraded,
+	})
+
 	response := &UptimeSeries{
 		Start:    first,
 		End:      last,
-		Uptime:   addDurations(allTimes, true),
-		Downtime: addDurations(allTimes, false),
+		Uptime:   addDurations(allTimes, true, false),
+		Degraded: addDurations(allTimes, true, true),
+		Downtime: addDurations(allTimes, false, false),
 		Series:   allTimes,
 	}
 
 	return response, nil
 }
 
-func addDurations(s []series, on bool) int64 {
+func addDurations(s []series, online, degraded bool) int64 {
 	var dur int64
 	for _, v := range s {
-		if v.Online == on {
+		if v.Online == online && v.Degraded == degraded {
 			dur += v.Duration
 		}
 	}
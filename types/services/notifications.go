@@ -25,10 +25,11 @@ func sendSuccess(s *Service) {
 
 	s.notifyAfterCount = 0
 
-	if s.prevOnline == s.Online {
+	if s.prevOnline == s.Online && !s.prevDegraded {
 		return
 	}
 	s.prevOnline = true
+	s.prevDegraded = false
 
 	for _, n := range allNotifiers {
 		notif := n.Select()
@@ -64,6 +65,7 @@ func sendFailure(s *Service, f *failures.Failure) {
 	}
 
 	s.prevOnline = false
+	s.prevDegraded = false
 
 	for _, n := range allNotifiers {
 		notif := n.Select()
@@ -82,6 +84,47 @@ func sendFailure(s *Service, f *failures.Failure) {
 	}
 }
 
+// sendDegraded will send a warning notification once when an online service becomes degraded
+func sendDegraded(s *Service, f *failures.Failure) {
+	if !s.AllowNotifications.Bool {
+		return
+	}
+
+	s.notifyAfterCount = 0
+
+	if s.prevDegraded {
+		return
+	}
+	s.prevOnline = true
+	s.prevDegraded = true
+
+	sendWarning(s, f)
+}
+
+// sendWarning will send a warning notification for a service that is still online, such as an expiring certificate
+func sendWarning(s *Service, f *failures.Failure) {
+	if !s.AllowNotifications.Bool {
+		return
+	}
+
+	for _, n := range allNotifiers {
+		notif := n.Select()
+		if notif.CanSend() {
+			log.Infof("Sending Warning notification to: %s!", notif.Method)
+			out, err := n.OnWarning(*s, *f)
+			if err != nil {
+				notif.Logger().WithField("warning", f.Issue).Errorln(err)
+				logMessage(notif.Method, "", err, false, s.Id)
+				continue
+			}
+			logMessage(notif.Method, out, nil, false, s.Id)
+
+			notif.LastSentCount++
+			notif.LastSent = utils.Now()
+		}
+	}
+}
+
 func logMessage(method string, msg string, error error, onSuccesss bool, serviceId int64) {
 	notif := FindNotifier(method)
 	l := &notifications.NotificationLog{
@@ -34,6 +34,7 @@ func FindNotifier(method string) *notifications.Notification {
 type ServiceNotifier interface {
 	OnSuccess(Service) (string, error)                   // OnSuccess is triggered when a service is successful
 	OnFailure(Service, failures.Failure) (string, error) // OnFailure is triggered when a service is failing
+	OnWarning(Service, failures.Failure) (string, error) // OnWarning is triggered when a service is online but needs attention
 	OnTest() (string, error)                             // OnTest is triggered for testing
 	OnSave() (string, error)                             // OnSave is triggered for testing
 	Select() *notifications.Notification                 // OnTest is triggered for testing
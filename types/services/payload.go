@@ -0,0 +1,101 @@
+package services
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/statping-ng/statping-ng/types/errors"
+)
+
+// decodePayload returns the bytes for a payload, hex payloads can contain spaces or colons between bytes
+func (s *Service) decodePayload(payload string) ([]byte, error) {
+	if s.PayloadEncoding != "hex" {
+		return []byte(payload), nil
+	}
+	clean := strings.NewReplacer(" ", "", ":", "", "\n", "", "\r", "", "\t", "").Replace(payload)
+	data, err := hex.DecodeString(clean)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid hex payload")
+	}
+	return data, nil
+}
+
+// encodeResponse returns the bytes received from a service in the same encoding as the payload
+func (s *Service) encodeResponse(data []byte) string {
+	if s.PayloadEncoding == "hex" {
+		return hex.EncodeToString(data)
+	}
+	return string(data)
+}
+
+// expectsResponse returns true if the service requires a response to match a regex or byte prefix
+func (s *Service) expectsResponse() bool {
+	return s.Expected.String != "" || s.ExpectedPrefix.String != ""
+}
+
+// matchResponse will check the response bytes against the expected byte prefix and regex of the service
+func (s *Service) matchResponse(data []byte) error {
+	if s.ExpectedPrefix.String != "" {
+		prefix, err := s.decodePayload(s.ExpectedPrefix.String)
+		if err != nil {
+			return err
+		}
+		if !bytes.HasPrefix(data, prefix) {
+			return fmt.Errorf("response '%v' did not start with '%v'", s.encodeResponse(data), s.ExpectedPrefix.String)
+		}
+	}
+	if s.Expected.String != "" {
+		match, err := regexp.Match(s.Expected.String, data)
+		if err != nil {
+			return err
+		}
+		if !match {
+			return fmt.Errorf("response '%v' did not match '%v'", s.encodeResponse(data), s.Expected.String)
+		}
+	}
+	return nil
+}
+
+// maxExpectSize is the most bytes read from a service while waiting for the expected response
+const maxExpectSize = 64 * 1024
+
+// sendExpect writes the payload to the connection and reads until the response matches the expected
+// byte prefix and regex of the service, or the connection deadline expires. The bytes received are always returned.
+func (s *Service) sendExpect(conn net.Conn, payload []byte) ([]byte, error) {
+	if len(payload) > 0 {
+		if _, err := conn.Write(payload); err != nil {
+			return nil, errors.Wrap(err, "write error")
+		}
+	}
+	if !s.expectsResponse() {
+		return nil, nil
+	}
+
+	var received []byte
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		received = append(received, buf[:n]...)
+		matchErr := s.matchResponse(received)
+		if matchErr == nil {
+			return received, nil
+		}
+		if len(received) >= maxExpectSize {
+			return received, matchErr
+		}
+		if err != nil {
+			if len(received) == 0 {
+				if err == io.EOF {
+					return received, errors.New("connection closed without a response")
+				}
+				return received, errors.Wrap(err, "no response received")
+			}
+			return received, matchErr
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/statping-ng/statping-ng/utils"
+)
+
+// proxyDirect connects a service directly even if the SERVICE_PROXY default is set
+const proxyDirect = "direct"
+
+// proxyTypes are the service types that can connect through a proxy
+var proxyTypes = map[string]bool{"http": true, "http_steps": true, "tcp": true, "grpc": true, "websocket": true}
+
+// proxyURL returns the proxy the service connects through. Services without a proxy use the SERVICE_PROXY
+// environment variable or config value, a proxy of 'direct' connects without any proxy.
+func (s *Service) proxyURL() string {
+	if !proxyTypes[s.Type] {
+		return ""
+	}
+	proxy := strings.TrimSpace(s.Proxy)
+	if proxy == "" && utils.Params != nil {
+		proxy = strings.TrimSpace(utils.Params.GetString("SERVICE_PROXY"))
+	}
+	if strings.EqualFold(proxy, proxyDirect) {
+		return ""
+	}
+	return proxy
+}
+
+// dialContext returns the function to connect to the service, through the proxy of the service if it has one
+func (s *Service) dialContext() (utils.DialFunc, error) {
+	dialer := &net.Dialer{
+		KeepAlive: time.Duration(s.Timeout) * time.Second,
+		Timeout:   time.Duration(s.Timeout) * time.Second,
+	}
+	if proxy := s.proxyURL(); proxy != "" {
+		return utils.ProxyDialer(proxy, dialer)
+	}
+	return dialer.DialContext, nil
+}
+
+// dialTLS connects with the dial function and completes the TLS handshake before the context is done
+func dialTLS(ctx context.Context, dial utils.DialFunc, network, addr string, config *tls.Config) (*tls.Conn, error) {
+	conn, err := dial(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/statping-ng/statping-ng/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testProxy is a local proxy that tunnels connections for the user 'statping' with the password 'secret',
+// it records the address of every tunnel it opens
+type testProxy struct {
+	mu      sync.Mutex
+	targets []string
+}
+
+func (p *testProxy) tunnel(client net.Conn, target string) {
+	p.mu.Lock()
+	p.targets = append(p.targets, target)
+	p.mu.Unlock()
+	conn, err := net.Dial("tcp", target)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	go io.Copy(conn, client)
+	io.Copy(client, conn)
+}
+
+func (p *testProxy) Targets() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string{}, p.targets...)
+}
+
+// serveConnect answers CONNECT requests
+func (p *testProxy) serveConnect(conn net.Conn) {
+	defer conn.Close()
+	req, err := http.ReadRequest(bufio.NewReader(conn))
+	if err != nil {
+		return
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte("statping:secret"))
+	if req.Method != "CONNECT" || req.Header.Get("Proxy-Authorization") != "Basic "+credentials {
+		conn.Write([]byte("HTTP/1.1 407 Proxy Authentication Required\r\n\r\n"))
+		return
+	}
+	conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+	p.tunnel(conn, req.Host)
+}
+
+// serveSocks answers SOCKS5 requests with username and password authentication
+func (p *testProxy) serveSocks(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return
+	}
+	io.ReadFull(r, make([]byte, header[1]))
+	conn.Write([]byte{5, 2})
+
+	readString := func() string {
+		size, _ := r.ReadByte()
+		value := make([]byte, size)
+		io.ReadFull(r, value)
+		return string(value)
+	}
+	r.ReadByte()
+	user, password := readString(), readString()
+	if user != "statping" || password != "secret" {
+		conn.Write([]byte{1, 1})
+		return
+	}
+	conn.Write([]byte{1, 0})
+
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(r, request); err != nil {
+		return
+	}
+	var host string
+	switch request[3] {
+	case 1:
+		ip := make([]byte, 4)
+		io.ReadFull(r, ip)
+		host = net.IP(ip).String()
+	case 3:
+		host = readString()
+	case 4:
+		ip := make([]byte, 16)
+		io.ReadFull(r, ip)
+		host = net.IP(ip).String()
+	}
+	port := make([]byte, 2)
+	io.ReadFull(r, port)
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	p.tunnel(conn, net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+}
+
+// testProxyServer starts the proxy and returns the address of its CONNECT and SOCKS5 listeners
+func testProxyServer(t *testing.T) (*testProxy, string, string) {
+	p := &testProxy{}
+	listen := func(serve func(net.Conn)) string {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.Nil(t, err)
+		t.Cleanup(func() { ln.Close() })
+		go func() {
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				go serve(conn)
+			}
+		}()
+		return ln.Addr().String()
+	}
+	return p, listen(p.serveConnect), listen(p.serveSocks)
+}
+
+func TestCheckProxy(t *testing.T) {
+	utils.InitEnvs()
+	p, connect, socks := testProxyServer(t)
+
+	web := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer web.Close()
+	addr, stop := tcpBannerServer(t)
+	defer stop()
+
+	server := grpcServer(50080, true)
+	defer server.Stop()
+
+	tests := []struct {
+		Name   string
+		Type   string
+		Domain string
+		Port   int
+		Proxy  string
+		Online bool
+		Target string
+	}{
+		{"HTTP over CONNECT", "http", web.URL, 0, "http://statping:secret@" + connect, true, web.Listener.Addr().String()},
+		{"HTTP over SOCKS5", "http", web.URL, 0, "socks5://statping:secret@" + socks, true, web.Listener.Addr().String()},
+		{"HTTP Proxy Denied", "http", web.URL, 0, "http://statping:wrong@" + connect, false, ""},
+		{"TCP over CONNECT", "tcp", "127.0.0.1", addr.Port, "http://statping:secret@" + connect, true, addr.String()},
+		{"TCP over SOCKS5", "tcp", "127.0.0.1", addr.Port, "socks5://statping:secret@" + socks, true, addr.String()},
+		{"TCP SOCKS5 Denied", "tcp", "127.0.0.1", addr.Port, "socks5://statping:wrong@" + socks, false, ""},
+		{"GRPC over SOCKS5", "grpc", "localhost", 50080, "socks5://statping:secret@" + socks, true, "localhost:50080"},
+		{"Unknown Scheme", "tcp", "127.0.0.1", addr.Port, "ftp://" + connect, false, ""},
+		{"Direct", "tcp", "127.0.0.1", addr.Port, proxyDirect, true, ""},
+	}
+
+	for _, v := range tests {
+		t.Run(v.Name, func(t *testing.T) {
+			before := len(p.Targets())
+			s := &Service{
+				Name:            v.Name,
+				Domain:          v.Domain,
+				Port:            v.Port,
+				Type:            v.Type,
+				Method:          "GET",
+				ExpectedStatus:  200,
+				Timeout:         2,
+				Expected:        null.NewNullString(""),
+				GrpcHealthCheck: null.NewNullBool(v.Type == "grpc"),
+				Proxy:           v.Proxy,
+			}
+			if v.Type == "grpc" {
+				s.ExpectedStatus = 1
+				s.Expected = null.NewNullString("status:SERVING")
+			}
+			s.CheckService(false)
+			assert.Equal(t, v.Online, s.Online)
+			targets := p.Targets()[before:]
+			if v.Target == "" {
+				assert.Empty(t, targets)
+				return
+			}
+			require.NotEmpty(t, targets)
+			assert.Equal(t, v.Target, targets[0])
+		})
+	}
+}
+
+func TestProxyDefault(t *testing.T) {
+	utils.InitEnvs()
+	p, connect, _ := testProxyServer(t)
+	addr, stop := tcpBannerServer(t)
+	defer stop()
+
+	utils.Params.Set("SERVICE_PROXY", fmt.Sprintf("http://statping:secret@%s", connect))
+	defer utils.Params.Set("SERVICE_PROXY", "")
+
+	s := &Service{Name: "Default Proxy", Domain: "127.0.0.1", Port: addr.Port, Type: "tcp", Timeout: 2}
+	assert.Equal(t, "http://statping:secret@"+connect, s.proxyURL())
+	s.CheckService(false)
+	assert.True(t, s.Online)
+	assert.Equal(t, []string{addr.String()}, p.Targets())
+
+	s.Proxy = proxyDirect
+	assert.Equal(t, "", s.proxyURL())
+
+	s = &Service{Name: "No Proxy Support", Domain: "127.0.0.1", Type: "icmp"}
+	assert.Equal(t, "", s.proxyURL())
+}
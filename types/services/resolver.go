@@ -0,0 +1,285 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/statping-ng/statping-ng/types/hits"
+)
+
+// IP families for the 'ip_family' of a service, an empty family connects to any address
+const (
+	familyIPv4 = "ipv4"
+	familyIPv6 = "ipv6"
+	familyBoth = "both"
+)
+
+// resolvesCustom returns true if the service resolves its host with its own resolver, override or IP family
+// instead of letting the check connect to the host name
+func (s *Service) resolvesCustom() bool {
+	return strings.TrimSpace(s.Resolver) != "" || strings.TrimSpace(s.ResolveOverride) != "" || s.IpFamily != ""
+}
+
+// connectAddress returns the IP address the check connects to instead of the host, the address
+// of a confirmation attempt comes first
+func (s *Service) connectAddress() string {
+	if s.confirmIP != "" {
+		return s.confirmIP
+	}
+	if s.familyIP != "" {
+		return s.familyIP
+	}
+	return s.resolvedIP
+}
+
+// familyLabel returns 'IPv4' or 'IPv6' for the address
+func familyLabel(ip net.IP) string {
+	if ip.To4() != nil {
+		return "IPv4"
+	}
+	return "IPv6"
+}
+
+// filterFamily returns the addresses of the IP family, all addresses if the family is empty or both
+func filterFamily(ips []net.IP, family string) []net.IP {
+	if family != familyIPv4 && family != familyIPv6 {
+		return ips
+	}
+	var filtered []net.IP
+	for _, ip := range ips {
+		if (ip.To4() != nil) == (family == familyIPv4) {
+			filtered = append(filtered, ip)
+		}
+	}
+	return filtered
+}
+
+// lookupAddresses returns the addresses of the host for the IP family of the service. The addresses of the
+// resolve override are used as is, otherwise the host is resolved with the service resolver or the system resolver.
+func (s *Service) lookupAddresses(host string) ([]net.IP, error) {
+	var ips []net.IP
+	if override := strings.TrimSpace(s.ResolveOverride); override != "" {
+		for _, value := range strings.FieldsFunc(override, func(r rune) bool { return r == ',' || r == ' ' }) {
+			ip := net.ParseIP(strings.Trim(value, "[]"))
+			if ip == nil {
+				return nil, fmt.Errorf("invalid resolve override address '%s'", value)
+			}
+			ips = append(ips, ip)
+		}
+	} else if ip := net.ParseIP(strings.Trim(host, "[]")); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		timeout := time.Duration(s.Timeout) * time.Second
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		var err error
+		if strings.TrimSpace(s.Resolver) == "" {
+			ips, err = net.DefaultResolver.LookupIP(ctx, "ip", host)
+		} else {
+			ips, err = s.resolverLookup(ctx, host)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ips = filterFamily(ips, s.IpFamily)
+	if len(ips) == 0 {
+		if s.IpFamily == familyIPv4 || s.IpFamily == familyIPv6 {
+			return nil, fmt.Errorf("no %s address for %s", strings.Replace(s.IpFamily, "ip", "IP", 1), host)
+		}
+		return nil, fmt.Errorf("no address for %s", host)
+	}
+	return ips, nil
+}
+
+// resolverLookup queries the A and AAAA records of the host from the resolver of the service, which is a
+// nameserver like '1.1.1.1', 'tcp://1.1.1.1:53' or a DNS-over-HTTPS URL like 'https://1.1.1.1/dns-query'
+func (s *Service) resolverLookup(ctx context.Context, host string) ([]net.IP, error) {
+	var qtypes []uint16
+	switch s.IpFamily {
+	case familyIPv4:
+		qtypes = []uint16{dns.TypeA}
+	case familyIPv6:
+		qtypes = []uint16{dns.TypeAAAA}
+	default:
+		qtypes = []uint16{dns.TypeA, dns.TypeAAAA}
+	}
+
+	var ips []net.IP
+	for _, qtype := range qtypes {
+		msg := new(dns.Msg)
+		msg.SetQuestion(dns.Fqdn(host), qtype)
+		msg.RecursionDesired = true
+		res, err := s.resolverExchange(ctx, msg)
+		if err != nil {
+			return nil, err
+		}
+		if res.Rcode != dns.RcodeSuccess {
+			return nil, fmt.Errorf("resolver responded with %s for %s", dns.RcodeToString[res.Rcode], host)
+		}
+		for _, rr := range res.Answer {
+			switch record := rr.(type) {
+			case *dns.A:
+				ips = append(ips, record.A)
+			case *dns.AAAA:
+				ips = append(ips, record.AAAA)
+			}
+		}
+	}
+	return ips, nil
+}
+
+// resolverExchange sends the query to the resolver of the service over UDP, TCP or HTTPS
+func (s *Service) resolverExchange(ctx context.Context, msg *dns.Msg) (*dns.Msg, error) {
+	resolver := strings.TrimSpace(s.Resolver)
+	if strings.HasPrefix(resolver, "https://") || strings.HasPrefix(resolver, "http://") {
+		return s.dohExchange(ctx, resolver, msg)
+	}
+
+	network := "udp"
+	if i := strings.Index(resolver, "://"); i >= 0 {
+		network, resolver = strings.ToLower(resolver[:i]), resolver[i+3:]
+		if network != "udp" && network != "tcp" {
+			return nil, fmt.Errorf("unknown resolver scheme '%s', use udp://, tcp:// or https://", network)
+		}
+	}
+	if _, _, err := net.SplitHostPort(resolver); err != nil {
+		resolver = net.JoinHostPort(strings.Trim(resolver, "[]"), "53")
+	}
+
+	c := &dns.Client{Net: network}
+	res, _, err := c.ExchangeContext(ctx, msg, resolver)
+	if err == nil && res.Truncated && network == "udp" {
+		c.Net = "tcp"
+		res, _, err = c.ExchangeContext(ctx, msg, resolver)
+	}
+	return res, err
+}
+
+// dohClient returns the HTTP client for a DNS-over-HTTPS resolver, it connects with the timeout and
+// proxy of the service and only verifies the certificate of the resolver if the service verifies SSL
+func (s *Service) dohClient() (*http.Client, error) {
+	dial, err := s.dialContext()
+	if err != nil {
+		return nil, err
+	}
+	timeout := time.Duration(s.Timeout) * time.Second
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: !s.VerifySSL.Bool,
+		},
+		DisableKeepAlives:     true,
+		ResponseHeaderTimeout: timeout,
+		TLSHandshakeTimeout:   timeout,
+		DialContext:           dial,
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// dohExchange sends the query as a DNS-over-HTTPS POST request (RFC 8484)
+func (s *Service) dohExchange(ctx context.Context, url string, msg *dns.Msg) (*dns.Msg, error) {
+	client, err := s.dohClient()
+	if err != nil {
+		return nil, err
+	}
+	msg.Id = 0
+	packed, err := msg.Pack()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(packed))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DNS-over-HTTPS resolver responded with status code %d", resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	res := new(dns.Msg)
+	if err := res.Unpack(body); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// checkFamilies runs the check for the first IPv4 and the first IPv6 address of the service. The latency
+// for each address is stored on the hit, the check fails if any of the addresses fails.
+func (s *Service) checkFamilies(record bool) error {
+	defer func() { s.familyIP = "" }()
+	s.familyLatencies = nil
+
+	t1 := time.Now()
+	ips, err := s.lookupAddresses(parseHost(s))
+	lookup := time.Since(t1).Microseconds()
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("Could not get IP address for service %v, %v", s.Domain, err), "lookup")
+		}
+		return err
+	}
+
+	var targets []net.IP
+	for _, family := range []string{familyIPv4, familyIPv6} {
+		addrs := filterFamily(ips, family)
+		if len(addrs) == 0 {
+			err = fmt.Errorf("no %s address for %s", strings.Replace(family, "ip", "IP", 1), parseHost(s))
+			if record {
+				RecordFailure(s, fmt.Sprintf("Could not get IP address for service %v, %v", s.Domain, err), family)
+			}
+			return err
+		}
+		targets = append(targets, addrs[0])
+	}
+
+	var latencies hits.StepLatencies
+	var soft []string
+	for _, ip := range targets {
+		s.familyIP = ip.String()
+		name := fmt.Sprintf("%s %s", familyLabel(ip), ip)
+		err := s.checkType(false)
+		if err != nil {
+			if record {
+				RecordFailure(s, fmt.Sprintf("%s: %v", name, err), strings.ToLower(familyLabel(ip)))
+			}
+			return err
+		}
+		latencies = append(latencies, hits.StepLatency{Name: name, Latency: s.Latency})
+		for _, failure := range s.softFailures {
+			soft = append(soft, fmt.Sprintf("%s: %s", name, failure))
+		}
+	}
+
+	// the service is as slow as its slowest address
+	for _, l := range latencies {
+		if l.Latency > s.Latency {
+			s.Latency = l.Latency
+		}
+	}
+	s.PingTime = lookup
+	s.softFailures = soft
+	s.familyLatencies = latencies
+	s.Online = true
+	if record {
+		RecordSuccess(s)
+	}
+	return nil
+}
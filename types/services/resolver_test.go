@@ -0,0 +1,244 @@
+package services
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/statping-ng/statping-ng/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resolverTestHandler answers 'backend.test' with the loopback addresses, 'v4only.test' only has an A record
+func resolverTestHandler(t *testing.T) dns.HandlerFunc {
+	records := []string{
+		"backend.test. 60 IN A 127.0.0.1",
+		"backend.test. 60 IN AAAA ::1",
+		"v4only.test. 60 IN A 127.0.0.1",
+	}
+	return func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		for _, v := range records {
+			rr, err := dns.NewRR(v)
+			require.Nil(t, err)
+			if rr.Header().Name == r.Question[0].Name && rr.Header().Rrtype == r.Question[0].Qtype {
+				m.Answer = append(m.Answer, rr)
+			}
+		}
+		if r.Question[0].Name != "backend.test." && r.Question[0].Name != "v4only.test." {
+			m.Rcode = dns.RcodeNameError
+		}
+		w.WriteMsg(m)
+	}
+}
+
+// resolverTestServers starts the nameserver on UDP and TCP, and as a DNS-over-HTTPS endpoint
+func resolverTestServers(t *testing.T) (string, string, string) {
+	handler := resolverTestHandler(t)
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+	udp := &dns.Server{PacketConn: pc, Handler: handler}
+	go udp.ActivateAndServe()
+	t.Cleanup(func() { udp.Shutdown() })
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	tcp := &dns.Server{Listener: listener, Handler: handler}
+	go tcp.ActivateAndServe()
+	t.Cleanup(func() { tcp.Shutdown() })
+
+	doh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		req := new(dns.Msg)
+		if r.Header.Get("Content-Type") != "application/dns-message" || req.Unpack(body) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		handler(&dohResponseWriter{w: w}, req)
+	}))
+	t.Cleanup(doh.Close)
+
+	return pc.LocalAddr().String(), "tcp://" + listener.Addr().String(), doh.URL + "/dns-query"
+}
+
+// dohResponseWriter writes the DNS response as the body of the HTTP response
+type dohResponseWriter struct {
+	dns.ResponseWriter
+	w http.ResponseWriter
+}
+
+func (d *dohResponseWriter) WriteMsg(m *dns.Msg) error {
+	packed, err := m.Pack()
+	if err != nil {
+		return err
+	}
+	d.w.Header().Set("Content-Type", "application/dns-message")
+	_, err = d.w.Write(packed)
+	return err
+}
+
+func TestLookupAddresses(t *testing.T) {
+	udp, tcp, doh := resolverTestServers(t)
+
+	tests := []struct {
+		Name     string
+		Host     string
+		Resolver string
+		Override string
+		Family   string
+		Expected []string
+		Error    string
+	}{
+		{"UDP Resolver", "backend.test", udp, "", "", []string{"127.0.0.1", "::1"}, ""},
+		{"TCP Resolver", "backend.test", tcp, "", familyIPv6, []string{"::1"}, ""},
+		{"DoH Resolver", "backend.test", doh, "", familyIPv4, []string{"127.0.0.1"}, ""},
+		{"Missing Family", "v4only.test", udp, "", familyIPv6, nil, "no IPv6 address for v4only.test"},
+		{"Unknown Host", "missing.test", udp, "", "", nil, "resolver responded with NXDOMAIN for missing.test"},
+		{"Unknown Scheme", "backend.test", "tls://127.0.0.1", "", "", nil, "unknown resolver scheme 'tls', use udp://, tcp:// or https://"},
+		{"Override", "backend.test", udp, "10.0.0.5, [2001:db8::5]", "", []string{"10.0.0.5", "2001:db8::5"}, ""},
+		{"Override Family", "backend.test", "", "10.0.0.5,2001:db8::5", familyIPv6, []string{"2001:db8::5"}, ""},
+		{"Invalid Override", "backend.test", "", "10.0.0.300", "", nil, "invalid resolve override address '10.0.0.300'"},
+		{"IP Address", "127.0.0.1", udp, "", familyIPv4, []string{"127.0.0.1"}, ""},
+	}
+
+	for _, v := range tests {
+		t.Run(v.Name, func(t *testing.T) {
+			s := &Service{Timeout: 2, Resolver: v.Resolver, ResolveOverride: v.Override, IpFamily: v.Family}
+			ips, err := s.lookupAddresses(v.Host)
+			if v.Error != "" {
+				require.NotNil(t, err)
+				assert.Equal(t, v.Error, err.Error())
+				return
+			}
+			require.Nil(t, err)
+			var addrs []string
+			for _, ip := range ips {
+				addrs = append(addrs, ip.String())
+			}
+			assert.Equal(t, v.Expected, addrs)
+		})
+	}
+}
+
+func TestCheckResolveOverride(t *testing.T) {
+	utils.InitEnvs()
+	udp, _, _ := resolverTestServers(t)
+
+	var hosts []string
+	listener, err := net.Listen("tcp", ":0")
+	require.Nil(t, err)
+	server := &httptest.Server{Listener: listener, Config: &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, r.Host)
+		w.Write([]byte("ok"))
+	})}}
+	server.Start()
+	t.Cleanup(server.Close)
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	s := &Service{
+		Name:            "Resolve Override",
+		Domain:          fmt.Sprintf("http://www.example.com:%d/health", port),
+		Type:            "http",
+		Method:          "GET",
+		ExpectedStatus:  200,
+		Timeout:         2,
+		ResolveOverride: "127.0.0.1",
+	}
+	s.CheckService(false)
+	assert.True(t, s.Online)
+	require.Len(t, hosts, 1)
+	assert.Equal(t, fmt.Sprintf("www.example.com:%d", port), hosts[0])
+
+	s = &Service{
+		Name:           "Both Families",
+		Domain:         fmt.Sprintf("http://backend.test:%d/health", port),
+		Type:           "http",
+		Method:         "GET",
+		ExpectedStatus: 200,
+		Timeout:        2,
+		Resolver:       udp,
+		IpFamily:       familyBoth,
+	}
+	s.CheckService(false)
+	assert.True(t, s.Online)
+	require.Len(t, s.familyLatencies, 2)
+	assert.Equal(t, "IPv4 127.0.0.1", s.familyLatencies[0].Name)
+	assert.Equal(t, "IPv6 ::1", s.familyLatencies[1].Name)
+
+	s = &Service{
+		Name:     "Missing IPv6",
+		Domain:   "v4only.test",
+		Port:     port,
+		Type:     "tcp",
+		Timeout:  2,
+		Resolver: udp,
+		IpFamily: familyBoth,
+	}
+	s.CheckService(false)
+	assert.False(t, s.Online)
+
+	s.IpFamily = familyIPv4
+	s.CheckService(false)
+	assert.True(t, s.Online)
+	assert.Equal(t, "127.0.0.1", s.resolvedIP)
+}
+
+func TestDohClient(t *testing.T) {
+	utils.InitEnvs()
+	handler := resolverTestHandler(t)
+	doh := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		req := new(dns.Msg)
+		if req.Unpack(body) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		handler(&dohResponseWriter{w: w}, req)
+	}))
+	t.Cleanup(doh.Close)
+	p, connect, _ := testProxyServer(t)
+
+	tests := []struct {
+		Name      string
+		Type      string
+		VerifySSL bool
+		Proxy     string
+		Error     string
+		Target    string
+	}{
+		{"Self Signed", "tcp", false, "", "", ""},
+		{"Verify Self Signed", "tcp", true, "", "certificate", ""},
+		{"Through Proxy", "http", false, "http://statping:secret@" + connect, "", doh.Listener.Addr().String()},
+	}
+
+	for _, v := range tests {
+		t.Run(v.Name, func(t *testing.T) {
+			before := len(p.Targets())
+			s := &Service{Type: v.Type, Timeout: 2, Resolver: doh.URL + "/dns-query", IpFamily: familyIPv4, VerifySSL: null.NewNullBool(v.VerifySSL), Proxy: v.Proxy}
+			ips, err := s.lookupAddresses("backend.test")
+			if v.Error != "" {
+				require.NotNil(t, err)
+				assert.Contains(t, err.Error(), v.Error)
+				return
+			}
+			require.Nil(t, err)
+			require.Len(t, ips, 1)
+			assert.Equal(t, "127.0.0.1", ips[0].String())
+			targets := p.Targets()[before:]
+			if v.Target == "" {
+				assert.Empty(t, targets)
+				return
+			}
+			require.NotEmpty(t, targets)
+			assert.Equal(t, v.Target, targets[0])
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/statping-ng/statping-ng/types/errors"
+)
+
+// confirms returns true if a failed check should be confirmed from a second IP address or resolver.
+// Confirmations are only supported for HTTP and TCP services.
+func (s *Service) confirms() bool {
+	if s.ConfirmIP == "" && s.ConfirmResolver == "" {
+		return false
+	}
+	return s.Type == "http" || s.Type == "tcp"
+}
+
+// checkAttempts are the attempts of a single check of a service. The scheduler keeps the attempts with the
+// queued check of the service, a manual check counts its own attempts and never changes the scheduled ones.
+type checkAttempts struct {
+	attempt  int
+	attempts int
+	online   bool
+	failed   bool
+}
+
+// retryPending returns true while the check has attempts left
+func (a *checkAttempts) retryPending() bool {
+	return a != nil && a.attempt < a.attempts
+}
+
+// confirmAddress returns the IP address used for the confirmation attempt, either the ConfirmIP
+// or the first address for the service host from the ConfirmResolver nameserver
+func (s *Service) confirmAddress() (string, error) {
+	if s.ConfirmIP != "" {
+		if net.ParseIP(s.ConfirmIP) == nil {
+			return "", fmt.Errorf("invalid confirmation IP address '%s'", s.ConfirmIP)
+		}
+		return s.ConfirmIP, nil
+	}
+	nameserver := strings.TrimSpace(s.ConfirmResolver)
+	if _, _, err := net.SplitHostPort(nameserver); err != nil {
+		nameserver = net.JoinHostPort(strings.Trim(nameserver, "[]"), "53")
+	}
+	timeout := time.Duration(s.Timeout) * time.Second
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			dialer := &net.Dialer{Timeout: timeout}
+			return dialer.DialContext(ctx, network, nameserver)
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	addrs, err := resolver.LookupHost(ctx, parseHost(s))
+	if err != nil {
+		return "", errors.Wrap(err, "confirmation lookup failed")
+	}
+	if len(addrs) == 0 {
+		return "", fmt.Errorf("no addresses for %s from %s", parseHost(s), nameserver)
+	}
+	return addrs[0], nil
+}
+
+// checkWithRetries runs the check for the service with its own attempts until it succeeds or all
+// attempts failed, waiting the retry delay between the attempts.
+func (s *Service) checkWithRetries(record bool) {
+	attempts := &checkAttempts{}
+	for s.checkAttempt(attempts, record) {
+		if s.RetryDelay > 0 {
+			time.Sleep(s.retryDelay())
+		}
+	}
+}
+
+// retryDelay returns the time to wait before the next attempt of a failed check
+func (s *Service) retryDelay() time.Duration {
+	return time.Duration(s.RetryDelay) * time.Second
+}
+
+// checkAttempt runs the next attempt of the check for the service and returns true if it failed and
+// another attempt is pending. Failures of attempts that are retried are only logged, the failure is
+// recorded once the last attempt failed. A successful confirmation from the second IP address only
+// suppresses the failure, the service keeps the state it had before the check.
+func (s *Service) checkAttempt(a *checkAttempts, record bool) bool {
+	if a.attempt == 0 {
+		a.attempts = s.Retries + 1
+		if s.confirms() {
+			a.attempts++
+		}
+		a.online = s.Online
+	}
+	a.attempt++
+
+	if a.attempt == a.attempts && s.confirms() {
+		ip, err := s.confirmAddress()
+		if err != nil {
+			log.Warnln(fmt.Sprintf("Service %v could not confirm failure: %v", s.Name, err))
+		}
+		s.confirmIP = ip
+	}
+	confirmed := s.confirmIP != ""
+	a.failed = false
+	s.attempts = a
+	err := s.runCheck(record)
+	s.attempts = nil
+	s.confirmIP = ""
+
+	failed := err != nil || a.failed
+	if failed && a.retryPending() {
+		return true
+	}
+	if !failed && confirmed {
+		s.Online = a.online
+	}
+	*a = checkAttempts{}
+	return false
+}
+
+// runCheck will run the check for the service, once for each IP family of HTTP
+// and TCP services that check both IPv4 and IPv6
+func (s *Service) runCheck(record bool) error {
+	s.familyLatencies = nil
+	if s.IpFamily == familyBoth && (s.Type == "http" || s.Type == "tcp") && s.confirmIP == "" {
+		return s.checkFamilies(record)
+	}
+	return s.checkType(record)
+}
+
+// checkType will run the check function for the service type
+func (s *Service) checkType(record bool) error {
+	var err error
+	switch s.Type {
+	case "http":
+		_, err = CheckHttp(s, record)
+	case "http_steps":
+		_, err = CheckHttpSteps(s, record)
+	case "tcp":
+		_, err = CheckTcp(s, record)
+	case "udp":
+		_, err = CheckUdp(s, record)
+	case "grpc":
+		_, err = CheckGrpc(s, record)
+	case "icmp":
+		_, err = CheckIcmp(s, record)
+	case "smtp":
+		_, err = CheckSmtp(s, record)
+	case "imap":
+		_, err = CheckImap(s, record)
+	case "redis":
+		_, err = CheckRedis(s, record)
+	case "amqp":
+		_, err = CheckAmqp(s, record)
+	case "mqtt":
+		_, err = CheckMqtt(s, record)
+	case "ssh":
+		_, err = CheckSsh(s, record)
+	case "ldap":
+		_, err = CheckLdap(s, record)
+	case "command":
+		_, err = CheckCommand(s, record)
+	case "dns":
+		_, err = CheckDns(s, record)
+	case "sql":
+		_, err = CheckSql(s, record)
+	case "websocket":
+		_, err = CheckWebsocket(s, record)
+	}
+	return err
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/statping-ng/statping-ng/database"
+	"github.com/statping-ng/statping-ng/types/hits"
+	"github.com/statping-ng/statping-ng/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRetries(t *testing.T) {
+	utils.InitEnvs()
+	var requests, failFirst int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= atomic.LoadInt32(&failFirst) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	s := &Service{
+		Name:           "HTTP Retries",
+		Domain:         server.URL,
+		Type:           "http",
+		Method:         "GET",
+		ExpectedStatus: 200,
+		Timeout:        2,
+		Retries:        2,
+	}
+
+	atomic.StoreInt32(&failFirst, 2)
+	s.CheckService(false)
+	assert.True(t, s.Online)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&requests))
+	assert.Nil(t, s.attempts)
+
+	s.Online = false
+	s.Retries = 1
+	atomic.StoreInt32(&requests, 0)
+	atomic.StoreInt32(&failFirst, 3)
+	s.CheckService(false)
+	assert.False(t, s.Online)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&requests))
+	assert.Nil(t, s.attempts)
+
+	// a manual check during a scheduled retry counts its own attempts
+	scheduled := &checkAttempts{}
+	atomic.StoreInt32(&requests, 0)
+	atomic.StoreInt32(&failFirst, 10)
+	assert.True(t, s.checkAttempt(scheduled, false))
+	assert.Equal(t, 1, scheduled.attempt)
+	s.CheckService(false)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&requests))
+	assert.Equal(t, 1, scheduled.attempt)
+	assert.Equal(t, 2, scheduled.attempts)
+	assert.False(t, s.checkAttempt(scheduled, false))
+	assert.Equal(t, checkAttempts{}, *scheduled)
+}
+
+func TestCheckConfirmation(t *testing.T) {
+	utils.InitEnvs()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.Nil(t, err)
+
+	// the domain cannot be resolved, only the confirmation attempt connects to the server
+	s := &Service{
+		Name:           "HTTP Confirmation",
+		Domain:         "http://statping-confirm.invalid:" + port,
+		Type:           "http",
+		Method:         "GET",
+		ExpectedStatus: 200,
+		Timeout:        2,
+	}
+	s.CheckService(false)
+	assert.False(t, s.Online)
+	assert.EqualValues(t, 0, atomic.LoadInt32(&requests))
+
+	// a successful confirmation suppresses the failure but keeps the state of the service
+	s.ConfirmIP = "127.0.0.1"
+	s.CheckService(false)
+	assert.False(t, s.Online)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&requests))
+	assert.Empty(t, s.confirmIP)
+
+	s.Online = true
+	s.CheckService(false)
+	assert.True(t, s.Online)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&requests))
+
+	// the confirmation attempt records a hit marked with the confirmation IP address
+	db, err := database.OpenTester()
+	require.Nil(t, err)
+	db.AutoMigrate(&hits.Hit{})
+	hits.SetDB(db)
+	confirming := &Service{Id: 9, Name: "Confirming", confirmIP: "127.0.0.1"}
+	RecordSuccess(confirming)
+	assert.False(t, confirming.LastOnline.IsZero())
+	hit := confirming.AllHits().Last()
+	require.NotNil(t, hit)
+	assert.Equal(t, "127.0.0.1", hit.ConfirmIP)
+
+	s.ConfirmIP = "not-an-ip"
+	_, err = s.confirmAddress()
+	assert.NotNil(t, err)
+
+	s.Type = "udp"
+	assert.False(t, s.confirms())
+}
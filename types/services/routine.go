@@ -11,6 +11,7 @@ import (
 	"net/smtp"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -26,42 +27,29 @@ import (
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
-// checkServices will start the checking go routine for each service
+// CheckServices will queue every service on the check scheduler
 func CheckServices() {
 	log.Infoln(fmt.Sprintf("Starting monitoring process for %v Services", len(allServices)))
 	for _, s := range allServices {
-		time.Sleep(50 * time.Millisecond)
-		go ServiceCheckQueue(s, true)
+		ServiceCheckQueue(s, true)
 	}
 }
 
-// CheckQueue is the main go routine for checking a service
+// ServiceCheckQueue starts the service and queues it on the check scheduler, the scheduler checks
+// the service every interval until the service is closed
 func ServiceCheckQueue(s *Service, record bool) {
 	s.Start()
 	s.Checkpoint = utils.Now()
-	s.SleepDuration = (time.Duration(s.Id) * 100) * time.Millisecond
-
-CheckLoop:
-	for {
-		select {
-		case <-s.Running:
-			log.Infoln(fmt.Sprintf("Stopping service: %v", s.Name))
-			break CheckLoop
-		case <-time.After(s.SleepDuration):
-			s.CheckService(record)
-			s.UpdateStats()
-			s.Checkpoint = s.Checkpoint.Add(s.Duration())
-			if !s.Online {
-				s.SleepDuration = s.Duration()
-			} else {
-				s.SleepDuration = s.Checkpoint.Sub(time.Now())
-			}
-		}
-	}
+	checkScheduler.add(s, record)
+}
+
+// ServiceCheckNow checks a queued service on the check scheduler as soon as possible
+func ServiceCheckNow(s *Service) {
+	checkScheduler.checkNow(s)
 }
 
 func parseHost(s *Service) string {
-	if s.Type == "tcp" || s.Type == "udp" || s.Type == "grpc" || s.Type == "smtp" || s.Type == "imap" {
+	if s.Type == "tcp" || s.Type == "udp" || s.Type == "grpc" || s.Type == "smtp" || s.Type == "imap" || s.Type == "redis" || s.Type == "mqtt" || s.Type == "amqp" || s.Type == "ssh" {
 		return s.Domain
 	} else {
 		u, err := url.Parse(s.Domain)
@@ -72,11 +60,28 @@ func parseHost(s *Service) string {
 	}
 }
 
-// dnsCheck will check the domain name and return a float64 for the amount of time the DNS check took
+// dnsCheck will check the domain name and return a float64 for the amount of time the DNS check took.
+// Services with a custom resolver, resolve override or IP family keep the first address to connect to.
 func dnsCheck(s *Service) (int64, error) {
+	s.resolvedIP = ""
+	if s.confirmIP != "" || s.familyIP != "" {
+		return 0, nil
+	}
+	if s.proxyURL() != "" && !s.resolvesCustom() {
+		// the proxy resolves the host of the service
+		return 0, nil
+	}
 	var err error
 	t1 := utils.Now()
 	host := parseHost(s)
+	if s.resolvesCustom() {
+		ips, err := s.lookupAddresses(host)
+		if err != nil {
+			return 0, err
+		}
+		s.resolvedIP = ips[0].String()
+		return utils.Now().Sub(t1).Microseconds(), nil
+	}
 	if s.Type == "tcp" || s.Type == "udp" || s.Type == "grpc" || s.Type == "smtp" {
 		_, err = net.LookupHost(host)
 	} else {
@@ -92,13 +97,23 @@ func isIPv6(address string) bool {
 	return strings.Count(address, ":") >= 2
 }
 
-// checkIcmp will send a ICMP ping packet to the service
+// CheckIcmp will send a burst of ICMP echo requests to the service, the packet count defaults to 1.
+// Assertions can check the 'packet_loss' percentage, the 'rtt_min', 'rtt_avg', 'rtt_max' and 'jitter' in milliseconds.
 func CheckIcmp(s *Service, record bool) (*Service, error) {
 	defer s.updateLastCheck()
 	timer := prometheus.NewTimer(metrics.ServiceTimer(s.Name))
 	defer timer.ObserveDuration()
 
-	dur, err := utils.Ping(s.Domain, s.Timeout)
+	assertions, err := s.ParseAssertions()
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("ICMP Assertions Error %v", err), "assertion")
+		}
+		return s, err
+	}
+
+	stats, err := utils.PingBurst(s.Domain, s.IcmpCount, s.Timeout)
+	s.pingStats = stats
 	if err != nil {
 		if record {
 			RecordFailure(s, fmt.Sprintf("Could not send ICMP to service %v, %v", s.Domain, err), "lookup")
@@ -106,9 +121,27 @@ func CheckIcmp(s *Service, record bool) (*Service, error) {
 		return s, err
 	}
 
-	s.PingTime = dur
-	s.Latency = dur
-	s.LastResponse = ""
+	s.PingTime = stats.MinRtt
+	s.Latency = stats.AvgRtt
+	s.LastResponse = fmt.Sprintf("%d/%d packets received, %.0f%% packet loss", stats.Received, stats.Sent, stats.PacketLoss)
+
+	s.softFailures = nil
+	if len(assertions) > 0 {
+		res := &assertionResponse{
+			Latency: time.Duration(stats.AvgRtt) * time.Microsecond,
+			Info:    icmpAssertionInfo(stats),
+		}
+		failed, soft := checkAssertions(assertions, res)
+		if len(failed) > 0 {
+			err = fmt.Errorf("%d of %d assertions failed", len(failed), len(assertions))
+			if record {
+				RecordFailure(s, fmt.Sprintf("ICMP Assertions failed: %s", strings.Join(failed, "; ")), "assertion")
+			}
+			return s, err
+		}
+		s.softFailures = soft
+	}
+
 	s.Online = true
 	if record {
 		RecordSuccess(s)
@@ -116,7 +149,22 @@ func CheckIcmp(s *Service, record bool) (*Service, error) {
 	return s, nil
 }
 
-// CheckGrpc will check a gRPC service
+// icmpAssertionInfo returns the statistics of the ping burst that assertions are checked against,
+// the round trip times are in milliseconds like the 'response_time' of other services
+func icmpAssertionInfo(stats *utils.PingStats) map[string]string {
+	ms := func(micro int64) string {
+		return strconv.FormatFloat(float64(micro)/1000, 'f', -1, 64)
+	}
+	return map[string]string{
+		"packet_loss": strconv.FormatFloat(stats.PacketLoss, 'f', -1, 64),
+		"rtt_min":     ms(stats.MinRtt),
+		"rtt_avg":     ms(stats.AvgRtt),
+		"rtt_max":     ms(stats.MaxRtt),
+		"jitter":      ms(stats.Jitter),
+	}
+}
+
+// CheckGrpc will check a gRPC service. With a method set, the method is called instead of the health check.
 func CheckGrpc(s *Service, record bool) (*Service, error) {
 	defer s.updateLastCheck()
 	timer := prometheus.NewTimer(metrics.ServiceTimer(s.Name))
@@ -146,15 +194,24 @@ func CheckGrpc(s *Service, record bool) (*Service, error) {
 		return s, err
 	}
 
+	dial, err := s.dialContext()
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("GRPC Proxy Error %v", err), "proxy")
+		}
+		return s, err
+	}
+
 	// Connect to grpc service without TLS certs.
 	grpcOption := grpc.WithInsecure()
 
 	// Check if TLS is enabled
 	// Upgrade GRPC connection if using TLS
 	// Force to connect on HTTP2 with TLS. Needed when using a reverse proxy such as nginx.
+	var tlsCapture *certificateCapture
 	if s.VerifySSL.Bool {
-		h2creds := credentials.NewTLS(&tls.Config{NextProtos: []string{"h2"}})
-		grpcOption = grpc.WithTransportCredentials(h2creds)
+		tlsCapture = newCertificateCapture(credentials.NewTLS(&tls.Config{NextProtos: []string{"h2"}}))
+		grpcOption = grpc.WithTransportCredentials(tlsCapture)
 	}
 
 	s.PingTime = dnsLookup
@@ -173,18 +230,38 @@ func CheckGrpc(s *Service, record bool) (*Service, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	conn, err := grpc.DialContext(ctx, domain, grpcOption, grpc.WithBlock())
+	grpcOptions := []grpc.DialOption{grpcOption, grpc.WithBlock()}
+	if s.proxyURL() != "" {
+		grpcOptions = append(grpcOptions, grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return dial(ctx, "tcp", addr)
+		}))
+	}
+
+	conn, err := grpc.DialContext(ctx, domain, grpcOptions...)
 	if err != nil {
+		if tlsCapture != nil && tlsCapture.State() != nil {
+			recordCertificate(s, tlsCapture.State(), s.Domain, false)
+		}
 		if record {
 			RecordFailure(s, fmt.Sprintf("Dial Error %v", err), "connection")
 		}
 		return s, err
 	}
 
+	if tlsCapture != nil {
+		recordCertificate(s, tlsCapture.State(), s.Domain, record)
+	}
+
+	ctx = s.grpcMetadata(ctx)
+	if s.GrpcMethod != "" {
+		defer conn.Close()
+		return s, s.checkGrpcMethod(ctx, conn, t1, record)
+	}
+
 	if s.GrpcHealthCheck.Bool {
 		// Create a new health check client
 		c := healthpb.NewHealthClient(conn)
-		in := &healthpb.HealthCheckRequest{}
+		in := &healthpb.HealthCheckRequest{Service: s.GrpcHealthService}
 		res, err := c.Check(ctx, in)
 		if err != nil {
 			if record {
@@ -248,11 +325,15 @@ func CheckTcp(s *Service, record bool) (*Service, error) {
 	}
 	s.PingTime = dnsLookup
 	t1 := utils.Now()
-	domain := fmt.Sprintf("%v", s.Domain)
+	host := s.Domain
+	if address := s.connectAddress(); address != "" {
+		host = address
+	}
+	domain := fmt.Sprintf("%v", host)
 	if s.Port != 0 {
-		domain = fmt.Sprintf("%v:%v", s.Domain, s.Port)
-		if isIPv6(s.Domain) {
-			domain = fmt.Sprintf("[%v]:%v", s.Domain, s.Port)
+		domain = fmt.Sprintf("%v:%v", host, s.Port)
+		if isIPv6(host) {
+			domain = fmt.Sprintf("[%v]:%v", host, s.Port)
 		}
 	}
 
@@ -261,34 +342,72 @@ func CheckTcp(s *Service, record bool) (*Service, error) {
 		log.Errorln(err)
 	}
 
+	dial, err := s.dialContext()
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("TCP Proxy Error: %v", err), "proxy")
+		}
+		return s, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout)*time.Second)
+	defer cancel()
+
 	// test TCP connection if there is no TLS Certificate set
+	var conn net.Conn
 	if s.TLSCert.String == "" {
-		conn, err := net.DialTimeout(s.Type, domain, time.Duration(s.Timeout)*time.Second)
+		conn, err = dial(ctx, s.Type, domain)
 		if err != nil {
 			if record {
 				RecordFailure(s, fmt.Sprintf("Dial Error: %v", err), "tls")
 			}
 			return s, err
 		}
-		defer conn.Close()
 	} else {
 		// test TCP connection if TLS Certificate was set
-		dialer := &net.Dialer{
-			KeepAlive: time.Duration(s.Timeout) * time.Second,
-			Timeout:   time.Duration(s.Timeout) * time.Second,
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		if tlsConfig.ServerName == "" {
+			// keep verifying the certificate against the domain when dialing the confirmation or resolved IP
+			tlsConfig = tlsConfig.Clone()
+			tlsConfig.ServerName = s.Domain
 		}
-		conn, err := tls.DialWithDialer(dialer, s.Type, domain, tlsConfig)
+		tlsConn, err := dialTLS(ctx, dial, s.Type, domain, tlsConfig)
 		if err != nil {
+			recordFailedCertificate(s, err, s.Domain)
 			if record {
 				RecordFailure(s, fmt.Sprintf("Dial Error: %v", err), "tls")
 			}
 			return s, err
 		}
-		defer conn.Close()
+		state := tlsConn.ConnectionState()
+		recordCertificate(s, &state, s.Domain, record)
+		conn = tlsConn
 	}
+	defer conn.Close()
 
-	s.Latency = utils.Now().Sub(t1).Microseconds()
 	s.LastResponse = ""
+	if s.Payload.String != "" || s.expectsResponse() {
+		payload, err := s.decodePayload(s.Payload.String)
+		if err != nil {
+			if record {
+				RecordFailure(s, fmt.Sprintf("TCP Payload Error: %v", err), "payload")
+			}
+			return s, err
+		}
+		conn.SetDeadline(t1.Add(time.Duration(s.Timeout) * time.Second))
+		received, err := s.sendExpect(conn, payload)
+		s.LastResponse = s.encodeResponse(received)
+		if err != nil {
+			s.Latency = utils.Now().Sub(t1).Microseconds()
+			if record {
+				RecordFailure(s, fmt.Sprintf("TCP %v", err), "response_body")
+			}
+			return s, err
+		}
+	}
+
+	s.Latency = utils.Now().Sub(t1).Microseconds()
 	s.Online = true
 	if record {
 		RecordSuccess(s)
@@ -565,6 +684,12 @@ func CheckHttp(s *Service, record bool) (*Service, error) {
 	if s.Redirect.Bool {
 		headers = append(headers, "Redirect=true")
 	}
+	if address := s.connectAddress(); address != "" {
+		headers = append(headers, "Connect-To="+address)
+	}
+	if proxy := s.proxyURL(); proxy != "" {
+		headers = append(headers, "Proxy="+proxy)
+	}
 
 	if s.PostData.String != "" {
 		data = bytes.NewBuffer([]byte(s.PostData.String))
@@ -583,8 +708,19 @@ func CheckHttp(s *Service, record bool) (*Service, error) {
 		log.Errorln(err)
 	}
 
+	assertions, err := s.ParseAssertions()
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("HTTP Assertions Error %v", err), "assertion")
+		}
+		return s, err
+	}
+
+	s.timing = nil
+	s.softFailures = nil
 	content, res, err = utils.HttpRequest(s.Domain, s.Method, contentType, headers, data, timeout, s.VerifySSL.Bool, customTLS)
 	if err != nil {
+		recordFailedCertificate(s, err, parseHost(s))
 		if record {
 			RecordFailure(s, fmt.Sprintf("HTTP Error %v", err), "request")
 		}
@@ -593,6 +729,10 @@ func CheckHttp(s *Service, record bool) (*Service, error) {
 	s.Latency = utils.Now().Sub(t1).Microseconds()
 	s.LastResponse = string(content)
 	s.LastStatusCode = res.StatusCode
+	s.timing = utils.RequestTiming(res)
+	if res.TLS != nil {
+		recordCertificate(s, res.TLS, res.Request.Host, record)
+	}
 
 	metrics.Gauge("status_code", float64(res.StatusCode), s.Name)
 
@@ -602,18 +742,44 @@ func CheckHttp(s *Service, record bool) (*Service, error) {
 			log.Warnln(fmt.Sprintf("Service %v expected: %v to match %v", s.Name, string(content), s.Expected.String))
 		}
 		if !match {
+			err = fmt.Errorf("HTTP Response Body did not match '%v'", s.Expected)
 			if record {
-				RecordFailure(s, fmt.Sprintf("HTTP Response Body did not match '%v'", s.Expected), "regex")
+				RecordFailure(s, err.Error(), "regex")
 			}
 			return s, err
 		}
 	}
-	if s.ExpectedStatus != res.StatusCode {
+	if len(assertions) > 0 {
+		failed, soft := checkAssertions(assertions, &assertionResponse{
+			StatusCode: res.StatusCode,
+			Header:     res.Header,
+			Body:       content,
+			Latency:    time.Duration(s.Latency) * time.Microsecond,
+		})
+		if len(failed) > 0 {
+			err = fmt.Errorf("%d of %d assertions failed", len(failed), len(assertions))
+			if record {
+				RecordFailure(s, fmt.Sprintf("HTTP Assertions failed: %s", strings.Join(failed, "; ")), "assertion")
+			}
+			return s, err
+		}
+		s.softFailures = soft
+	}
+	if !hasStatusAssertion(assertions) && s.ExpectedStatus != res.StatusCode {
+		err = fmt.Errorf("HTTP Status Code %v did not match %v", res.StatusCode, s.ExpectedStatus)
 		if record {
-			RecordFailure(s, fmt.Sprintf("HTTP Status Code %v did not match %v", res.StatusCode, s.ExpectedStatus), "status_code")
+			RecordFailure(s, err.Error(), "status_code")
 		}
 		return s, err
 	}
+	if s.ContentCheck.Bool {
+		if err := s.checkContent(content, record); err != nil {
+			if record {
+				RecordFailure(s, fmt.Sprintf("HTTP Content Error %v", err), "content")
+			}
+			return s, err
+		}
+	}
 	if record {
 		RecordSuccess(s)
 	}
@@ -625,26 +791,77 @@ func CheckHttp(s *Service, record bool) (*Service, error) {
 func RecordSuccess(s *Service) {
 	s.LastOnline = utils.Now()
 	s.Online = true
+	s.diagnosed = false
+	s.updateDegraded()
 	hit := &hits.Hit{
 		Service:   s.Id,
 		Latency:   s.Latency,
 		PingTime:  s.PingTime,
+		Degraded:  s.Degraded,
+		ConfirmIP: s.confirmIP,
 		CreatedAt: utils.Now(),
 	}
+	if s.Type == "http_steps" {
+		hit.Steps = s.stepLatencies()
+	}
+	if s.Type == "websocket" {
+		hit.Steps = s.wsLatencies
+	}
+	if s.Type == "command" {
+		hit.Perfdata = s.Perfdata
+	}
+	if len(s.familyLatencies) > 0 {
+		hit.Steps = s.familyLatencies
+	}
+	if s.Type == "icmp" && s.pingStats != nil {
+		hit.RttMin = s.pingStats.MinRtt
+		hit.RttAvg = s.pingStats.AvgRtt
+		hit.RttMax = s.pingStats.MaxRtt
+		hit.Jitter = s.pingStats.Jitter
+		hit.PacketLoss = s.pingStats.PacketLoss
+	}
+	if s.Type == "http" && s.timing != nil {
+		hit.DnsLookup = s.timing.DnsLookup.Microseconds()
+		hit.TcpConnect = s.timing.TcpConnect.Microseconds()
+		hit.TlsHandshake = s.timing.TlsHandshake.Microseconds()
+		hit.FirstByte = s.timing.FirstByte.Microseconds()
+		hit.ContentTransfer = s.timing.ContentTransfer.Microseconds()
+	}
 	if err := hit.Create(); err != nil {
 		log.Error(err)
 	}
 	log.WithFields(utils.ToFields(hit, s)).Infoln(
-		fmt.Sprintf("Service #%d '%v' Successful Response: %s | Lookup in: %s | Online: %v | Interval: %d seconds", s.Id, s.Name, humanMicro(hit.Latency), humanMicro(hit.PingTime), s.Online, s.Interval))
+		fmt.Sprintf("Service #%d '%v' Successful Response: %s | Lookup in: %s | Status: %v | Interval: %d seconds", s.Id, s.Name, humanMicro(hit.Latency), humanMicro(hit.PingTime), s.Status(), s.Interval))
 	s.LastLookupTime = hit.PingTime
 	s.LastLatency = hit.Latency
-	metrics.Gauge("online", 1., s.Name, s.Type)
+	metrics.Gauge("online", s.onlineGauge(), s.Name, s.Type)
 	metrics.Inc("success", s.Name)
+	if s.confirmIP != "" {
+		// the service keeps its state, only the next check of the service address sends notifications
+		log.Infoln(fmt.Sprintf("Service %v failure was not confirmed from %v", s.Name, s.confirmIP))
+		return
+	}
+	if s.Degraded {
+		log.WithFields(utils.ToFields(hit, s)).Warnln(fmt.Sprintf("Service %v Degraded: %v", s.Name, s.DegradedIssue))
+		sendDegraded(s, &failures.Failure{
+			Service:   s.Id,
+			Issue:     s.DegradedIssue,
+			PingTime:  s.PingTime,
+			Reason:    "degraded",
+			CreatedAt: utils.Now(),
+		})
+		return
+	}
 	sendSuccess(s)
 }
 
 // RecordFailure will create a new 'Failure' record in the database for a offline service
 func RecordFailure(s *Service, issue, reason string) {
+	if a := s.attempts; a.retryPending() {
+		a.failed = true
+		log.Warnln(fmt.Sprintf("Service %v attempt %d of %d failed: %v", s.Name, a.attempt, a.attempts, issue))
+		return
+	}
 	s.LastOffline = utils.Now()
 
 	fail := &failures.Failure{
@@ -655,6 +872,14 @@ func RecordFailure(s *Service, issue, reason string) {
 		ErrorCode: s.LastStatusCode,
 		Reason:    reason,
 	}
+	if s.attempts != nil && s.attempts.attempt > 1 {
+		fail.Attempts = s.attempts.attempt
+	}
+	// diagnostics are only collected for the first failure after the service was online
+	if s.Diagnostics.Bool && diagnosticTypes[s.Type] && !s.diagnosed {
+		fail.Diagnostics = s.collectDiagnostics()
+		s.diagnosed = true
+	}
 	log.WithFields(utils.ToFields(fail, s)).
 		Warnln(fmt.Sprintf("Service %v Failing: %v | Lookup in: %v", s.Name, issue, humanMicro(fail.PingTime)))
 
@@ -662,6 +887,7 @@ func RecordFailure(s *Service, issue, reason string) {
 		log.Error(err)
 	}
 	s.Online = false
+	s.updateDegraded()
 	s.DownText = s.DowntimeText()
 
 	limitOffset := len(s.Failures)
@@ -678,19 +904,8 @@ func RecordFailure(s *Service, issue, reason string) {
 
 // Check will run checkHttp for HTTP services and checkTcp for TCP services
 // if record param is set to true, it will add a record into the database.
+// A failed check is retried up to 'Retries' times before the failure is recorded.
 func (s *Service) CheckService(record bool) {
-	switch s.Type {
-	case "http":
-		CheckHttp(s, record)
-	case "tcp", "udp":
-		CheckTcp(s, record)
-	case "grpc":
-		CheckGrpc(s, record)
-	case "icmp":
-		CheckIcmp(s, record)
-	case "smtp":
-		CheckSmtp(s, record)
-	case "imap":
-		CheckImap(s, record)
-	}
+	s.checkWithRetries(record)
+	s.updateDegraded()
 }
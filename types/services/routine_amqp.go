@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/statping-ng/statping-ng/types/metrics"
+	"github.com/statping-ng/statping-ng/utils"
+)
+
+// amqpURI returns the connection URL for the service, using 'amqps' when TLS is enabled
+func (s *Service) amqpURI() string {
+	scheme, port := "amqp", 5672
+	if s.requiresTLS() || s.TLSCert.String != "" {
+		scheme, port = "amqps", 5671
+	}
+	if s.Port != 0 {
+		port = s.Port
+	}
+	return fmt.Sprintf("%s://%s/", scheme, net.JoinHostPort(s.Domain, strconv.Itoa(port)))
+}
+
+// isAmqpAuthError returns true if the broker refused the credentials or the access to the vhost
+func isAmqpAuthError(err error) bool {
+	e, ok := err.(*amqp.Error)
+	return ok && e.Code == amqp.AccessRefused
+}
+
+// amqpProbe consumes from the probe queue, publishes a unique message to it and returns the round-trip
+func amqpProbe(ch *amqp.Channel, queue string, timeout time.Duration) (time.Duration, error) {
+	if _, err := ch.QueueDeclare(queue, false, true, false, false, nil); err != nil {
+		return 0, err
+	}
+	deliveries, err := ch.Consume(queue, "", true, false, false, false, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	id := fmt.Sprintf("statping-%d-%s", utils.Now().UnixNano(), utils.RandomString(8))
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	t1 := utils.Now()
+	err = ch.PublishWithContext(ctx, "", queue, false, false, amqp.Publishing{
+		MessageId:  id,
+		Timestamp:  t1,
+		Expiration: strconv.FormatInt(timeout.Milliseconds(), 10),
+		Body:       []byte(id),
+	})
+	if err != nil {
+		return 0, err
+	}
+	for {
+		select {
+		case msg, ok := <-deliveries:
+			if !ok {
+				return 0, fmt.Errorf("channel closed before the probe message was delivered")
+			}
+			if msg.MessageId == id {
+				return utils.Now().Sub(t1), nil
+			}
+		case <-ctx.Done():
+			return 0, fmt.Errorf("probe message was not delivered within %v", timeout)
+		}
+	}
+}
+
+// CheckAmqp will open a channel to the vhost of an 'amqp' service and passively declare the queue to assert
+// on its message and consumer count. With a probe queue, the latency is the round-trip of a probe message.
+func CheckAmqp(s *Service, record bool) (*Service, error) {
+	defer s.updateLastCheck()
+	timer := prometheus.NewTimer(metrics.ServiceTimer(s.Name))
+	defer timer.ObserveDuration()
+
+	assertions, err := s.ParseAssertions()
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("AMQP Assertions Error %v", err), "assertion")
+		}
+		return s, err
+	}
+
+	dnsLookup, err := dnsCheck(s)
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("Could not get IP address for AMQP service %v, %v", s.Domain, err), "lookup")
+		}
+		return s, err
+	}
+	s.PingTime = dnsLookup
+
+	tlsConfig, err := s.LoadTLSCert()
+	if err != nil {
+		log.Errorln(err)
+	}
+	timeout := time.Duration(s.Timeout) * time.Second
+	config := amqp.Config{
+		Vhost:           s.AmqpVhost,
+		TLSClientConfig: tlsConfig,
+		Locale:          "en_US",
+		Dial:            amqp.DefaultDial(timeout),
+	}
+	if username, password := s.headerCredentials(); username != "" {
+		config.SASL = []amqp.Authentication{&amqp.PlainAuth{Username: username, Password: password}}
+	}
+
+	t1 := utils.Now()
+	conn, err := amqp.DialConfig(s.amqpURI(), config)
+	if err != nil {
+		if record {
+			if isAmqpAuthError(err) {
+				RecordFailure(s, fmt.Sprintf("AMQP Authentication Error: %v", err), "authentication")
+			} else {
+				RecordFailure(s, fmt.Sprintf("AMQP Connection Error: %v", err), "connection")
+			}
+		}
+		return s, err
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("AMQP Channel Error: %v", err), "channel")
+		}
+		return s, err
+	}
+	defer ch.Close()
+
+	res := &assertionResponse{Info: make(map[string]string)}
+	if s.AmqpQueue != "" {
+		queue, err := ch.QueueDeclarePassive(s.AmqpQueue, false, false, false, false, nil)
+		if err != nil {
+			if record {
+				RecordFailure(s, fmt.Sprintf("AMQP Queue Error: %v", err), "queue")
+			}
+			return s, err
+		}
+		res.Info["message_count"] = strconv.Itoa(queue.Messages)
+		res.Info["consumer_count"] = strconv.Itoa(queue.Consumers)
+		s.LastResponse = fmt.Sprintf("%d messages, %d consumers", queue.Messages, queue.Consumers)
+	}
+	s.Latency = utils.Now().Sub(t1).Microseconds()
+
+	if s.AmqpProbeQueue != "" {
+		latency, err := amqpProbe(ch, s.AmqpProbeQueue, timeout)
+		if err != nil {
+			if record {
+				RecordFailure(s, fmt.Sprintf("AMQP Probe Error: %v", err), "probe")
+			}
+			return s, err
+		}
+		s.Latency = latency.Microseconds()
+	}
+
+	s.softFailures = nil
+	if len(assertions) > 0 {
+		res.Latency = time.Duration(s.Latency) * time.Microsecond
+		failed, soft := checkAssertions(assertions, res)
+		if len(failed) > 0 {
+			err = fmt.Errorf("%d of %d assertions failed", len(failed), len(assertions))
+			if record {
+				RecordFailure(s, fmt.Sprintf("AMQP Assertions failed: %s", strings.Join(failed, "; ")), "assertion")
+			}
+			return s, err
+		}
+		s.softFailures = soft
+	}
+
+	s.Online = true
+	if record {
+		RecordSuccess(s)
+	}
+	return s, nil
+}
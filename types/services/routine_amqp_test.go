@@ -0,0 +1,238 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// amqpFrame is a frame of the AMQP 0-9-1 wire protocol
+type amqpFrame struct {
+	Type    byte
+	Channel uint16
+	Payload []byte
+}
+
+func readAmqpFrame(r io.Reader) (*amqpFrame, error) {
+	header := make([]byte, 7)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	payload := make([]byte, binary.BigEndian.Uint32(header[3:])+1)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	return &amqpFrame{Type: header[0], Channel: binary.BigEndian.Uint16(header[1:]), Payload: payload[:len(payload)-1]}, nil
+}
+
+func writeAmqpFrame(w io.Writer, f *amqpFrame) {
+	header := make([]byte, 7)
+	header[0] = f.Type
+	binary.BigEndian.PutUint16(header[1:], f.Channel)
+	binary.BigEndian.PutUint32(header[3:], uint32(len(f.Payload)))
+	w.Write(append(append(header, f.Payload...), 0xCE))
+}
+
+// amqpMethod encodes a method frame payload, string arguments are written as short strings
+func amqpMethod(class, method uint16, args ...interface{}) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, class)
+	binary.Write(&buf, binary.BigEndian, method)
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case string:
+			buf.WriteByte(byte(len(v)))
+			buf.WriteString(v)
+		case []byte:
+			binary.Write(&buf, binary.BigEndian, uint32(len(v)))
+			buf.Write(v)
+		default:
+			binary.Write(&buf, binary.BigEndian, v)
+		}
+	}
+	return buf.Bytes()
+}
+
+// amqpShortString returns the short string at the offset of the payload and the offset after it
+func amqpShortString(payload []byte, offset int) (string, int) {
+	size := int(payload[offset])
+	return string(payload[offset+1 : offset+1+size]), offset + 1 + size
+}
+
+type amqpTestQueue struct {
+	Messages  uint32
+	Consumers uint32
+}
+
+// amqpTestBroker starts a local AMQP broker that requires the password and knows the queues.
+// Probe messages are delivered back to the consumer, unless they are published to 'blackhole'.
+func amqpTestBroker(t *testing.T, password string, queues map[string]amqpTestQueue) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	var mu sync.Mutex
+	handle := func(conn net.Conn) {
+		defer conn.Close()
+		if _, err := io.ReadFull(conn, make([]byte, 8)); err != nil {
+			return
+		}
+		writeAmqpFrame(conn, &amqpFrame{Type: 1, Payload: amqpMethod(10, 10, uint8(0), uint8(9), uint32(0), []byte("PLAIN"), []byte("en_US"))})
+
+		consumers := make(map[string]string)
+		var publish *amqpFrame
+		var publishHeader *amqpFrame
+		for {
+			f, err := readAmqpFrame(conn)
+			if err != nil {
+				return
+			}
+			switch f.Type {
+			case 2:
+				publishHeader = f
+				continue
+			case 3:
+				exchange, offset := amqpShortString(publish.Payload, 6)
+				key, _ := amqpShortString(publish.Payload, offset)
+				if tag, ok := consumers[key]; ok && key != "blackhole" {
+					writeAmqpFrame(conn, &amqpFrame{Type: 1, Channel: f.Channel, Payload: amqpMethod(60, 60, tag, uint64(1), uint8(0), exchange, key)})
+					writeAmqpFrame(conn, publishHeader)
+					writeAmqpFrame(conn, f)
+				}
+				continue
+			case 1:
+			default:
+				continue
+			}
+
+			class, method := binary.BigEndian.Uint16(f.Payload), binary.BigEndian.Uint16(f.Payload[2:])
+			reply := func(class, method uint16, args ...interface{}) {
+				writeAmqpFrame(conn, &amqpFrame{Type: 1, Channel: f.Channel, Payload: amqpMethod(class, method, args...)})
+			}
+			switch uint32(class)<<16 | uint32(method) {
+			case 10<<16 | 11: // connection.start-ok
+				offset := 4 + 4 + int(binary.BigEndian.Uint32(f.Payload[4:]))
+				_, offset = amqpShortString(f.Payload, offset)
+				size := int(binary.BigEndian.Uint32(f.Payload[offset:]))
+				response := strings.Split(string(f.Payload[offset+4:offset+4+size]), "\x00")
+				if response[len(response)-1] != password {
+					return
+				}
+				reply(10, 30, uint16(0), uint32(131072), uint16(0))
+			case 10<<16 | 40: // connection.open
+				reply(10, 41, "")
+			case 10<<16 | 50: // connection.close
+				reply(10, 51)
+				return
+			case 20<<16 | 10: // channel.open
+				reply(20, 11, []byte{})
+			case 20<<16 | 40: // channel.close
+				reply(20, 41)
+			case 50<<16 | 10: // queue.declare
+				name, offset := amqpShortString(f.Payload, 6)
+				passive := f.Payload[offset]&1 == 1
+				mu.Lock()
+				queue, ok := queues[name]
+				if !ok && !passive {
+					queues[name] = queue
+					ok = true
+				}
+				mu.Unlock()
+				if !ok {
+					reply(20, 40, uint16(404), "NOT_FOUND - no queue '"+name+"' in vhost '/'", uint16(50), uint16(10))
+					continue
+				}
+				reply(50, 11, name, queue.Messages, queue.Consumers)
+			case 60<<16 | 20: // basic.consume
+				name, offset := amqpShortString(f.Payload, 6)
+				consumers[name], _ = amqpShortString(f.Payload, offset)
+				reply(60, 21, consumers[name])
+			case 60<<16 | 30: // basic.cancel
+				tag, _ := amqpShortString(f.Payload, 4)
+				reply(60, 31, tag)
+			case 60<<16 | 40: // basic.publish
+				publish = f
+			}
+		}
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go handle(conn)
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestCheckAmqp(t *testing.T) {
+	port := amqpTestBroker(t, "secret", map[string]amqpTestQueue{
+		"work":   {Messages: 12, Consumers: 2},
+		"orphan": {Messages: 0, Consumers: 0},
+	})
+
+	tests := []struct {
+		Name       string
+		Headers    string
+		Queue      string
+		Probe      string
+		Assertions string
+		Error      string
+	}{
+		{"Connection Only", "Username=statping,Password=secret", "", "", "", ""},
+		{"Wrong Password", "Username=statping,Password=wrong", "", "", "", "Exception (403) Reason: \"username or password not allowed\""},
+		{"Queue Depth", "Username=statping,Password=secret", "work", "", `[{"source":"message_count","comparison":"less_than","value":"100"},{"source":"consumer_count","comparison":"greater_than","value":"0"}]`, ""},
+		{"Too Many Messages", "Username=statping,Password=secret", "work", "", `[{"source":"message_count","comparison":"less_than","value":"10"}]`, "1 of 1 assertions failed"},
+		{"No Consumers", "Username=statping,Password=secret", "orphan", "", `[{"source":"consumer_count","comparison":"greater_than","value":"0"}]`, "1 of 1 assertions failed"},
+		{"Missing Queue", "Username=statping,Password=secret", "missing", "", "", "Exception (404) Reason: \"NOT_FOUND - no queue 'missing' in vhost '/'\""},
+		{"Probe", "Username=statping,Password=secret", "work", "statping.probe", "", ""},
+		{"Probe Not Delivered", "Username=statping,Password=secret", "", "blackhole", "", "probe message was not delivered within 1s"},
+	}
+
+	for _, v := range tests {
+		t.Run(v.Name, func(t *testing.T) {
+			s := &Service{
+				Name:           "AMQP " + v.Name,
+				Domain:         "127.0.0.1",
+				Port:           port,
+				Type:           "amqp",
+				Timeout:        1,
+				Headers:        null.NewNullString(v.Headers),
+				Assertions:     null.NewNullString(v.Assertions),
+				AmqpQueue:      v.Queue,
+				AmqpProbeQueue: v.Probe,
+			}
+			_, err := CheckAmqp(s, false)
+			if v.Error == "" {
+				require.Nil(t, err)
+				assert.True(t, s.Online)
+				assert.Greater(t, s.Latency, int64(0))
+			} else {
+				require.NotNil(t, err)
+				assert.Equal(t, v.Error, err.Error())
+				assert.False(t, s.Online)
+			}
+		})
+	}
+}
+
+func TestAmqpURI(t *testing.T) {
+	s := &Service{Domain: "rabbit.local", Type: "amqp"}
+	assert.Equal(t, "amqp://rabbit.local:5672/", s.amqpURI())
+	s.VerifySSL = null.NewNullBool(true)
+	assert.Equal(t, "amqps://rabbit.local:5671/", s.amqpURI())
+	s.Port = 15671
+	s.Domain = "::1"
+	assert.Equal(t, "amqps://[::1]:15671/", s.amqpURI())
+}
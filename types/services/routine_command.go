@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/statping-ng/statping-ng/types/hits"
+	"github.com/statping-ng/statping-ng/types/metrics"
+	"github.com/statping-ng/statping-ng/utils"
+)
+
+// Nagios plugin exit codes
+const (
+	nagiosOk       = 0
+	nagiosWarning  = 1
+	nagiosCritical = 2
+)
+
+// commandLines returns the non-empty lines of a newline delimited field
+func commandLines(value string) []string {
+	var lines []string
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// parseNagiosOutput returns the first line of the plugin output and the performance data after the '|'
+// separators, which can be on the first line and on any line of the long output.
+func parseNagiosOutput(output string) (string, hits.Perfdata) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	summary := lines[0]
+	var perf []string
+	if i := strings.Index(summary, "|"); i >= 0 {
+		perf = append(perf, summary[i+1:])
+		summary = summary[:i]
+	}
+	inPerf := false
+	for _, line := range lines[1:] {
+		if inPerf {
+			perf = append(perf, line)
+		} else if i := strings.Index(line, "|"); i >= 0 {
+			perf = append(perf, line[i+1:])
+			inPerf = true
+		}
+	}
+	return strings.TrimSpace(summary), parsePerfdata(strings.Join(perf, " "))
+}
+
+// parsePerfdata parses space separated Nagios metrics like 'label'=value[UOM];[warn];[crit];[min];[max].
+// Labels containing spaces are quoted, metrics without a numeric value are skipped.
+func parsePerfdata(perfdata string) hits.Perfdata {
+	var perf hits.Perfdata
+	for _, field := range splitPerfdata(perfdata) {
+		i := strings.LastIndex(field, "=")
+		if i <= 0 {
+			continue
+		}
+		label := strings.ReplaceAll(strings.Trim(field[:i], "'"), "''", "'")
+		parts := strings.Split(field[i+1:], ";")
+		value := parts[0]
+		end := strings.LastIndexAny(value, "0123456789.") + 1
+		number, err := strconv.ParseFloat(value[:end], 64)
+		if err != nil {
+			continue
+		}
+		metric := hits.PerfMetric{Label: label, Value: number, Unit: value[end:]}
+		for n, threshold := range []*string{&metric.Warn, &metric.Crit, &metric.Min, &metric.Max} {
+			if n+1 < len(parts) {
+				*threshold = parts[n+1]
+			}
+		}
+		perf = append(perf, metric)
+	}
+	return perf
+}
+
+// splitPerfdata splits the performance data on spaces outside of quoted labels
+func splitPerfdata(perfdata string) []string {
+	var fields []string
+	var field strings.Builder
+	quoted := false
+	for _, r := range perfdata {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case (r == ' ' || r == '\t') && !quoted:
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+			continue
+		}
+		field.WriteRune(r)
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
+
+// CheckCommand will run the executable of a 'command' service with its arguments and environment variables,
+// and read the result like a Nagios plugin. The exit code 0 is online, 1 is degraded, 2 and 3 are offline.
+func CheckCommand(s *Service, record bool) (*Service, error) {
+	defer s.updateLastCheck()
+	timer := prometheus.NewTimer(metrics.ServiceTimer(s.Name))
+	defer timer.ObserveDuration()
+
+	timeout := time.Duration(s.Timeout) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	t1 := utils.Now()
+	stdout, stderr, err := utils.CommandContext(ctx, commandLines(s.CommandEnv.String), s.Domain, commandLines(s.CommandArgs.String)...)
+	s.Latency = utils.Now().Sub(t1).Microseconds()
+
+	code := nagiosOk
+	if err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		switch {
+		case ctx.Err() != nil:
+			err = fmt.Errorf("command did not finish within %v", timeout)
+			if record {
+				RecordFailure(s, fmt.Sprintf("Command Timeout: %v", err), "timeout")
+			}
+			return s, err
+		case !ok:
+			if record {
+				RecordFailure(s, fmt.Sprintf("Command Error: %v", err), "command")
+			}
+			return s, err
+		}
+		code = exitErr.ExitCode()
+	}
+
+	output := stdout
+	if strings.TrimSpace(output) == "" {
+		output = stderr
+	}
+	summary, perfdata := parseNagiosOutput(output)
+	s.LastResponse = summary
+	s.Perfdata = perfdata
+	if summary == "" {
+		summary = fmt.Sprintf("exit code %d", code)
+	}
+
+	s.softFailures = nil
+	switch code {
+	case nagiosOk:
+	case nagiosWarning:
+		s.softFailures = []string{fmt.Sprintf("WARNING: %s", summary)}
+	case nagiosCritical:
+		err = fmt.Errorf("CRITICAL: %s", summary)
+		if record {
+			RecordFailure(s, err.Error(), "critical")
+		}
+		return s, err
+	default: // 3 is UNKNOWN, other exit codes are treated the same
+		err = fmt.Errorf("UNKNOWN: %s", summary)
+		if record {
+			RecordFailure(s, err.Error(), "unknown")
+		}
+		return s, err
+	}
+
+	s.Online = true
+	if record {
+		RecordSuccess(s)
+	}
+	return s, nil
+}
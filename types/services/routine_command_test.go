@@ -0,0 +1,87 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/statping-ng/statping-ng/types/hits"
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkPluginScript prints the status and exits with the code given as arguments, like a Nagios plugin
+const checkPluginScript = `#!/bin/sh
+if [ "$1" = "sleep" ]; then
+  sleep 5
+fi
+echo "$2 - queue has $QUEUE_SIZE jobs | 'queue size'=${QUEUE_SIZE};100;500;0 time=0.25s;1;2"
+echo "long output"
+exit $1
+`
+
+func TestCheckCommand(t *testing.T) {
+	plugin := filepath.Join(t.TempDir(), "check_queue")
+	require.Nil(t, os.WriteFile(plugin, []byte(checkPluginScript), 0755))
+
+	tests := []struct {
+		Name     string
+		Domain   string
+		Args     string
+		Online   bool
+		Degraded bool
+		Error    string
+	}{
+		{"OK", plugin, "0\nOK", true, false, ""},
+		{"Warning", plugin, "1\nWARNING", true, true, ""},
+		{"Critical", plugin, "2\nCRITICAL", false, false, "CRITICAL: CRITICAL - queue has 42 jobs"},
+		{"Unknown", plugin, "3\nUNKNOWN", false, false, "UNKNOWN: UNKNOWN - queue has 42 jobs"},
+		{"Timeout", plugin, "sleep\nOK", false, false, "command did not finish within 1s"},
+		{"Not Found", filepath.Join(t.TempDir(), "missing"), "", false, false, "no such file or directory"},
+	}
+
+	for _, v := range tests {
+		t.Run(v.Name, func(t *testing.T) {
+			s := &Service{
+				Name:        "Command " + v.Name,
+				Domain:      v.Domain,
+				Type:        "command",
+				Timeout:     1,
+				CommandArgs: null.NewNullString(v.Args),
+				CommandEnv:  null.NewNullString("QUEUE_SIZE=42\n"),
+			}
+			_, err := CheckCommand(s, false)
+			if v.Online {
+				require.Nil(t, err)
+				assert.True(t, s.Online)
+				assert.Equal(t, v.Degraded, len(s.softFailures) > 0)
+				require.Len(t, s.Perfdata, 2)
+				assert.Equal(t, hits.PerfMetric{Label: "queue size", Value: 42, Warn: "100", Crit: "500", Min: "0"}, s.Perfdata[0])
+			} else {
+				require.NotNil(t, err)
+				assert.Contains(t, err.Error(), v.Error)
+				assert.False(t, s.Online)
+			}
+		})
+	}
+}
+
+func TestParseNagiosOutput(t *testing.T) {
+	summary, perf := parseNagiosOutput("DISK OK - free space: / 3326 MB (56%); | /=2643MB;5948;5958;0;5968\n/ 15272 MB (77%);\n/boot 68 MB (69%);\n/home 69357 MB (27%); | /boot=68MB;88;93;0;98\n/home=69357MB;253404;253409;0;253414 \n")
+	assert.Equal(t, "DISK OK - free space: / 3326 MB (56%);", summary)
+	assert.Equal(t, hits.Perfdata{
+		{Label: "/", Value: 2643, Unit: "MB", Warn: "5948", Crit: "5958", Min: "0", Max: "5968"},
+		{Label: "/boot", Value: 68, Unit: "MB", Warn: "88", Crit: "93", Min: "0", Max: "98"},
+		{Label: "/home", Value: 69357, Unit: "MB", Warn: "253404", Crit: "253409", Min: "0", Max: "253414"},
+	}, perf)
+
+	summary, perf = parseNagiosOutput("PING OK - Packet loss = 0%\n")
+	assert.Equal(t, "PING OK - Packet loss = 0%", summary)
+	assert.Empty(t, perf)
+
+	assert.Equal(t, hits.Perfdata{
+		{Label: "it's load", Value: 0.5, Warn: "~:1", Crit: "@2:3"},
+		{Label: "used", Value: 93.5, Unit: "%"},
+	}, parsePerfdata("'it''s load'=0.5;~:1;@2:3 skipped=U used=93.5%"))
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/statping-ng/statping-ng/types/errors"
+	"github.com/statping-ng/statping-ng/types/metrics"
+)
+
+// dnsRecordTypes are the record types a 'dns' service can query
+var dnsRecordTypes = map[string]uint16{
+	"A":     dns.TypeA,
+	"AAAA":  dns.TypeAAAA,
+	"CNAME": dns.TypeCNAME,
+	"MX":    dns.TypeMX,
+	"TXT":   dns.TypeTXT,
+	"NS":    dns.TypeNS,
+	"SRV":   dns.TypeSRV,
+	"CAA":   dns.TypeCAA,
+}
+
+// dnsAnswer is a single record returned from a DNS query
+type dnsAnswer struct {
+	Value string
+	TTL   uint32
+}
+
+// dnsNameserver returns the nameserver address for a 'dns' service, falling back
+// to the first nameserver configured in /etc/resolv.conf
+func dnsNameserver(s *Service) (string, error) {
+	server := strings.TrimSpace(s.DnsServer.String)
+	if server == "" {
+		conf, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+		if err != nil {
+			return "", errors.Wrap(err, "no nameserver configured")
+		}
+		if len(conf.Servers) == 0 {
+			return "", errors.New("no nameserver configured")
+		}
+		return net.JoinHostPort(conf.Servers[0], conf.Port), nil
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		return net.JoinHostPort(strings.Trim(server, "[]"), "53"), nil
+	}
+	return server, nil
+}
+
+// dnsQuery sends a DNS query for the service domain and returns the answers that match the record type
+func dnsQuery(s *Service, nameserver string, qtype uint16) ([]dnsAnswer, time.Duration, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(s.Domain), qtype)
+	msg.RecursionDesired = true
+
+	c := &dns.Client{Net: "udp", Timeout: time.Duration(s.Timeout) * time.Second}
+	res, rtt, err := c.Exchange(msg, nameserver)
+	if err == nil && res.Truncated {
+		c.Net = "tcp"
+		res, rtt, err = c.Exchange(msg, nameserver)
+	}
+	if err != nil {
+		return nil, rtt, err
+	}
+	if res.Rcode != dns.RcodeSuccess {
+		return nil, rtt, fmt.Errorf("nameserver responded with %s", dns.RcodeToString[res.Rcode])
+	}
+
+	var answers []dnsAnswer
+	for _, rr := range res.Answer {
+		if rr.Header().Rrtype != qtype {
+			continue
+		}
+		answers = append(answers, dnsAnswer{Value: dnsRecordValue(rr), TTL: rr.Header().Ttl})
+	}
+	return answers, rtt, nil
+}
+
+// dnsRecordValue returns the text form of a DNS record without its header
+func dnsRecordValue(rr dns.RR) string {
+	switch v := rr.(type) {
+	case *dns.A:
+		return v.A.String()
+	case *dns.AAAA:
+		return v.AAAA.String()
+	case *dns.CNAME:
+		return strings.TrimSuffix(v.Target, ".")
+	case *dns.MX:
+		return fmt.Sprintf("%d %s", v.Preference, strings.TrimSuffix(v.Mx, "."))
+	case *dns.TXT:
+		return strings.Join(v.Txt, "")
+	case *dns.NS:
+		return strings.TrimSuffix(v.Ns, ".")
+	case *dns.SRV:
+		return fmt.Sprintf("%d %d %d %s", v.Priority, v.Weight, v.Port, strings.TrimSuffix(v.Target, "."))
+	case *dns.CAA:
+		return fmt.Sprintf("%d %s %s", v.Flag, v.Tag, v.Value)
+	}
+	return strings.TrimPrefix(rr.String(), rr.Header().String())
+}
+
+// dnsExpected splits the expected answers of a 'dns' service by new line, TXT records may contain commas
+func dnsExpected(expected string) []string {
+	var values []string
+	for _, v := range strings.Split(expected, "\n") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		values = append(values, strings.ToLower(strings.TrimSuffix(v, ".")))
+	}
+	sort.Strings(values)
+	return values
+}
+
+// dnsTTLBounds describes the TTL bounds of a 'dns' service, a bound of 0 is not set
+func dnsTTLBounds(min, max int) string {
+	switch {
+	case min > 0 && max > 0:
+		return fmt.Sprintf("outside of %d-%d seconds", min, max)
+	case min > 0:
+		return fmt.Sprintf("below the minimum of %d seconds", min)
+	default:
+		return fmt.Sprintf("above the maximum of %d seconds", max)
+	}
+}
+
+// matchDnsAnswers will compare the returned records with the expected answers of the service
+func matchDnsAnswers(s *Service, values []string) error {
+	if s.Expected.String == "" {
+		return nil
+	}
+	switch s.DnsMatch {
+	case "regex":
+		match, err := regexp.MatchString(s.Expected.String, strings.Join(values, "\n"))
+		if err != nil {
+			return err
+		}
+		if !match {
+			return fmt.Errorf("records %v did not match '%v'", values, s.Expected.String)
+		}
+	case "contains":
+		found := make(map[string]bool)
+		for _, v := range values {
+			found[strings.ToLower(v)] = true
+		}
+		for _, v := range dnsExpected(s.Expected.String) {
+			if !found[v] {
+				return fmt.Errorf("records %v did not contain '%v'", values, v)
+			}
+		}
+	default:
+		var got []string
+		for _, v := range values {
+			got = append(got, strings.ToLower(v))
+		}
+		sort.Strings(got)
+		expected := dnsExpected(s.Expected.String)
+		if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+			return fmt.Errorf("records %v did not match expected %v", values, expected)
+		}
+	}
+	return nil
+}
+
+// CheckDns will query a nameserver for a DNS record and assert on the returned answers
+func CheckDns(s *Service, record bool) (*Service, error) {
+	defer s.updateLastCheck()
+	timer := prometheus.NewTimer(metrics.ServiceTimer(s.Name))
+	defer timer.ObserveDuration()
+
+	recordType := strings.ToUpper(s.DnsRecordType)
+	if recordType == "" {
+		recordType = "A"
+	}
+	qtype, ok := dnsRecordTypes[recordType]
+	if !ok {
+		err := fmt.Errorf("unsupported DNS record type '%v'", s.DnsRecordType)
+		if record {
+			RecordFailure(s, fmt.Sprintf("DNS Error: %v", err), "dns_type")
+		}
+		return s, err
+	}
+
+	nameserver, err := dnsNameserver(s)
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("DNS Error: %v", err), "dns_server")
+		}
+		return s, err
+	}
+
+	answers, rtt, err := dnsQuery(s, nameserver, qtype)
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("DNS %s query for %v to %v failed, %v", recordType, s.Domain, nameserver, err), "lookup")
+		}
+		return s, err
+	}
+	s.PingTime = rtt.Microseconds()
+	s.Latency = rtt.Microseconds()
+
+	var values []string
+	for _, a := range answers {
+		values = append(values, a.Value)
+	}
+	s.LastResponse = strings.Join(values, "\n")
+
+	if len(answers) == 0 {
+		err = fmt.Errorf("no %s records found for %v", recordType, s.Domain)
+		if record {
+			RecordFailure(s, fmt.Sprintf("DNS Error: %v", err), "dns_answer")
+		}
+		return s, err
+	}
+
+	if err := matchDnsAnswers(s, values); err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("DNS %s %v", recordType, err), "dns_answer")
+		}
+		return s, err
+	}
+
+	for _, a := range answers {
+		if (s.DnsMinTTL > 0 && a.TTL < uint32(s.DnsMinTTL)) || (s.DnsMaxTTL > 0 && a.TTL > uint32(s.DnsMaxTTL)) {
+			err = fmt.Errorf("TTL %d for record '%v' is %s", a.TTL, a.Value, dnsTTLBounds(s.DnsMinTTL, s.DnsMaxTTL))
+			if record {
+				RecordFailure(s, fmt.Sprintf("DNS %s %v", recordType, err), "dns_ttl")
+			}
+			return s, err
+		}
+	}
+
+	s.Online = true
+	if record {
+		RecordSuccess(s)
+	}
+	return s, nil
+}
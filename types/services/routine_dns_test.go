@@ -0,0 +1,96 @@
+package services
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dnsServer starts a local nameserver with a few records for example.com
+func dnsServer(t *testing.T) (string, func()) {
+	records := []string{
+		"example.com. 300 IN A 10.0.0.1",
+		"example.com. 300 IN A 10.0.0.2",
+		"example.com. 3600 IN MX 10 mail.example.com.",
+		"example.com. 60 IN TXT \"v=spf1 -all\"",
+		"example.com. 60 IN TXT \"k=rsa, p=MIGfMA0GCSqGSIb3\"",
+	}
+	mux := dns.NewServeMux()
+	mux.HandleFunc("example.com.", func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		for _, v := range records {
+			rr, err := dns.NewRR(v)
+			require.Nil(t, err)
+			if rr.Header().Rrtype == r.Question[0].Qtype {
+				m.Answer = append(m.Answer, rr)
+			}
+		}
+		w.WriteMsg(m)
+	})
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+	server := &dns.Server{PacketConn: pc, Handler: mux}
+	go server.ActivateAndServe()
+	return pc.LocalAddr().String(), func() { server.Shutdown() }
+}
+
+func TestCheckDns(t *testing.T) {
+	addr, stop := dnsServer(t)
+	defer stop()
+
+	tests := []struct {
+		Name    string
+		Record  string
+		Match   string
+		Expect  string
+		MinTTL  int
+		MaxTTL  int
+		Online  bool
+		Records string
+	}{
+		{"Exact A Records", "A", "exact", "10.0.0.2\n10.0.0.1", 0, 0, true, "10.0.0.1\n10.0.0.2"},
+		{"Drifted A Records", "A", "exact", "10.0.0.1", 0, 0, false, "10.0.0.1\n10.0.0.2"},
+		{"Contains A Record", "A", "contains", "10.0.0.2", 0, 0, true, "10.0.0.1\n10.0.0.2"},
+		{"Missing A Record", "A", "contains", "10.0.0.3", 0, 0, false, "10.0.0.1\n10.0.0.2"},
+		{"MX Regex", "MX", "regex", `^10 mail\.example\.com$`, 0, 0, true, "10 mail.example.com"},
+		{"TXT Exact", "TXT", "exact", "v=spf1 -all\nk=rsa, p=MIGfMA0GCSqGSIb3", 0, 0, true, "v=spf1 -all\nk=rsa, p=MIGfMA0GCSqGSIb3"},
+		{"TXT Contains Comma", "TXT", "contains", "k=rsa, p=MIGfMA0GCSqGSIb3", 0, 0, true, "v=spf1 -all\nk=rsa, p=MIGfMA0GCSqGSIb3"},
+		{"TTL Within Bounds", "A", "", "", 60, 600, true, "10.0.0.1\n10.0.0.2"},
+		{"TTL Too Low", "TXT", "", "", 120, 0, false, "v=spf1 -all\nk=rsa, p=MIGfMA0GCSqGSIb3"},
+		{"TTL Too High", "A", "", "", 0, 120, false, "10.0.0.1\n10.0.0.2"},
+		{"No Records", "AAAA", "", "", 0, 0, false, ""},
+		{"Unsupported Type", "PTR", "", "", 0, 0, false, ""},
+	}
+
+	for _, v := range tests {
+		t.Run(v.Name, func(t *testing.T) {
+			s := &Service{
+				Name:          v.Name,
+				Domain:        "example.com",
+				Type:          "dns",
+				Timeout:       2,
+				DnsServer:     null.NewNullString(addr),
+				DnsRecordType: v.Record,
+				DnsMatch:      v.Match,
+				DnsMinTTL:     v.MinTTL,
+				DnsMaxTTL:     v.MaxTTL,
+				Expected:      null.NewNullString(v.Expect),
+			}
+			s.CheckService(false)
+			assert.Equal(t, v.Online, s.Online)
+			assert.Equal(t, v.Records, s.LastResponse)
+		})
+	}
+}
+
+func TestDnsTTLBounds(t *testing.T) {
+	assert.Equal(t, "outside of 60-600 seconds", dnsTTLBounds(60, 600))
+	assert.Equal(t, "below the minimum of 120 seconds", dnsTTLBounds(120, 0))
+	assert.Equal(t, "above the maximum of 120 seconds", dnsTTLBounds(0, 120))
+}
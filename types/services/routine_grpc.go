@@ -0,0 +1,263 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/statping-ng/statping-ng/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// grpcMetadata adds the headers of the service as gRPC metadata to the outgoing context
+func (s *Service) grpcMetadata(ctx context.Context) context.Context {
+	if !s.Headers.Valid {
+		return ctx
+	}
+	var pairs []string
+	for _, h := range strings.Split(s.Headers.String, ",") {
+		keyVal := strings.SplitN(h, "=", 2)
+		if len(keyVal) == 2 && strings.TrimSpace(keyVal[0]) != "" {
+			pairs = append(pairs, strings.ToLower(strings.TrimSpace(keyVal[0])), strings.TrimSpace(keyVal[1]))
+		}
+	}
+	if len(pairs) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// grpcExpectedCode returns the gRPC status code a method call is expected to return. A stored 0 is replaced
+// by the column default of 200, so that means OK. A health check service keeps its expected SERVING status 1
+// when a method is added, so for those 1 means OK as well instead of CANCELLED.
+func (s *Service) grpcExpectedCode() codes.Code {
+	if s.ExpectedStatus == 200 || (s.ExpectedStatus == 1 && s.GrpcHealthCheck.Bool) {
+		return codes.OK
+	}
+	return codes.Code(s.ExpectedStatus)
+}
+
+// checkGrpcMethod calls the unary method of the service with the post data as JSON request. The gRPC status code
+// is checked against the expected status code, see grpcExpectedCode, and the JSON response against the
+// expected regex and the assertions.
+func (s *Service) checkGrpcMethod(ctx context.Context, conn *grpc.ClientConn, t1 time.Time, record bool) error {
+	assertions, err := s.ParseAssertions()
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("GRPC Assertions Error %v", err), "assertion")
+		}
+		return err
+	}
+
+	s.softFailures = nil
+	code, body, header, err := grpcInvoke(ctx, conn, s.GrpcMethod, s.PostData.String)
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("GRPC Method Error %v", err), "method")
+		}
+		return err
+	}
+	s.Latency = utils.Now().Sub(t1).Microseconds()
+	s.LastResponse = string(body)
+	s.LastStatusCode = int(code)
+
+	if s.Expected.String != "" {
+		match, err := regexp.MatchString(s.Expected.String, s.LastResponse)
+		if err != nil {
+			log.Warnln(fmt.Sprintf("Service %v expected: %v to match %v", s.Name, s.LastResponse, s.Expected.String))
+		}
+		if !match {
+			err = fmt.Errorf("GRPC Response '%v' did not match '%v'", s.LastResponse, s.Expected.String)
+			if record {
+				RecordFailure(s, err.Error(), "regex")
+			}
+			return err
+		}
+	}
+	if len(assertions) > 0 {
+		failed, soft := checkAssertions(assertions, &assertionResponse{
+			StatusCode: int(code),
+			Header:     header,
+			Body:       body,
+			Latency:    time.Duration(s.Latency) * time.Microsecond,
+		})
+		if len(failed) > 0 {
+			err = fmt.Errorf("%d of %d assertions failed", len(failed), len(assertions))
+			if record {
+				RecordFailure(s, fmt.Sprintf("GRPC Assertions failed: %s", strings.Join(failed, "; ")), "assertion")
+			}
+			return err
+		}
+		s.softFailures = soft
+	}
+	if expected := s.grpcExpectedCode(); !hasStatusAssertion(assertions) && expected != code {
+		err = fmt.Errorf("GRPC Status Code %v (%v) did not match %v (%v)", int(code), code, int(expected), expected)
+		if record {
+			RecordFailure(s, err.Error(), "status_code")
+		}
+		return err
+	}
+
+	s.Online = true
+	if record {
+		RecordSuccess(s)
+	}
+	return nil
+}
+
+// grpcInvoke calls the unary method like 'package.Service/Method' with the JSON request. The method is
+// looked up with server reflection, the response is returned as JSON with the header and trailer metadata.
+// A call that fails with a gRPC status returns the status code without an error.
+func grpcInvoke(ctx context.Context, conn *grpc.ClientConn, method, request string) (codes.Code, []byte, http.Header, error) {
+	md, err := grpcMethodDescriptor(ctx, conn, method)
+	if err != nil {
+		return codes.Unknown, nil, nil, err
+	}
+
+	in := dynamicpb.NewMessage(md.Input())
+	if strings.TrimSpace(request) != "" {
+		if err := protojson.Unmarshal([]byte(request), in); err != nil {
+			return codes.Unknown, nil, nil, fmt.Errorf("invalid request for %s, %v", md.Input().FullName(), err)
+		}
+	}
+	out := dynamicpb.NewMessage(md.Output())
+
+	var head, trailer metadata.MD
+	fullMethod := fmt.Sprintf("/%s/%s", md.Parent().FullName(), md.Name())
+	err = conn.Invoke(ctx, fullMethod, in, out, grpc.Header(&head), grpc.Trailer(&trailer))
+
+	header := make(http.Header)
+	for _, md := range []metadata.MD{head, trailer} {
+		for k, values := range md {
+			for _, v := range values {
+				header.Add(k, v)
+			}
+		}
+	}
+	if err != nil {
+		st, ok := status.FromError(err)
+		if !ok {
+			return codes.Unknown, nil, header, err
+		}
+		return st.Code(), []byte(st.Message()), header, nil
+	}
+
+	body, err := protojson.Marshal(out)
+	if err != nil {
+		return codes.OK, nil, header, err
+	}
+	// protojson randomly adds whitespace, compact it to keep the response stable
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, body); err != nil {
+		return codes.OK, nil, header, err
+	}
+	return codes.OK, compact.Bytes(), header, nil
+}
+
+// grpcMethodDescriptor finds the unary method with the server reflection service of the server
+func grpcMethodDescriptor(ctx context.Context, conn *grpc.ClientConn, method string) (protoreflect.MethodDescriptor, error) {
+	method = strings.TrimPrefix(strings.TrimSpace(method), "/")
+	i := strings.LastIndexAny(method, "/.")
+	if i <= 0 || i == len(method)-1 {
+		return nil, fmt.Errorf("invalid method '%s', use 'package.Service/Method'", method)
+	}
+	serviceName, methodName := method[:i], method[i+1:]
+
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.CloseSend()
+
+	files, err := grpcReflectFiles(stream, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, fmt.Errorf("service %s not found, %v", serviceName, err)
+	}
+	sd, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", serviceName)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(methodName))
+	if md == nil {
+		return nil, fmt.Errorf("service %s has no method %s", serviceName, methodName)
+	}
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return nil, fmt.Errorf("method %s is streaming, only unary methods can be called", method)
+	}
+	return md, nil
+}
+
+// grpcReflectFiles requests the file that defines the symbol and all of its dependencies
+func grpcReflectFiles(stream rpb.ServerReflection_ServerReflectionInfoClient, symbol string) (*protoregistry.Files, error) {
+	protos := make(map[string]*descriptorpb.FileDescriptorProto)
+	requested := make(map[string]bool)
+	req := &rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	}
+	for req != nil {
+		if err := stream.Send(req); err != nil {
+			return nil, err
+		}
+		res, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if e := res.GetErrorResponse(); e != nil {
+			return nil, fmt.Errorf("server reflection error %d, %s", e.GetErrorCode(), e.GetErrorMessage())
+		}
+		for _, b := range res.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fd := new(descriptorpb.FileDescriptorProto)
+			if err := proto.Unmarshal(b, fd); err != nil {
+				return nil, err
+			}
+			protos[fd.GetName()] = fd
+		}
+
+		// request the dependencies the server did not send along
+		req = nil
+		for _, fd := range protos {
+			for _, dep := range fd.GetDependency() {
+				if _, ok := protos[dep]; ok {
+					continue
+				}
+				if requested[dep] {
+					return nil, fmt.Errorf("server reflection did not return %s", dep)
+				}
+				requested[dep] = true
+				req = &rpb.ServerReflectionRequest{
+					MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+				}
+				break
+			}
+			if req != nil {
+				break
+			}
+		}
+	}
+
+	set := new(descriptorpb.FileDescriptorSet)
+	for _, fd := range protos {
+		set.File = append(set.File, fd)
+	}
+	return protodesc.NewFiles(set)
+}
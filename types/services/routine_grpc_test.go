@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// grpcReflectionServer starts a gRPC server with the health and reflection services. The health of the
+// 'statping.Storage' subsystem is not serving, requests without the 'x-api-key' metadata are unauthenticated.
+func grpcReflectionServer(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if info.FullMethod == "/grpc.health.v1.Health/Check" {
+			md, _ := metadata.FromIncomingContext(ctx)
+			if len(md.Get("x-api-key")) == 0 || md.Get("x-api-key")[0] != "secret" {
+				return nil, status.Error(codes.Unauthenticated, "missing api key")
+			}
+			grpc.SetHeader(ctx, metadata.Pairs("x-subsystem", "storage"))
+		}
+		return handler(ctx, req)
+	}))
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("statping.Storage", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestCheckGrpcMethod(t *testing.T) {
+	port := grpcReflectionServer(t)
+
+	tests := []struct {
+		Name           string
+		HealthService  string
+		Method         string
+		Request        string
+		ExpectedStatus int
+		Expected       string
+		Assertions     string
+		Online         bool
+		StatusCode     int
+		Response       string
+	}{
+		{"Health Check", "", "", "", 1, "status:SERVING", "", true, 1, "status:SERVING"},
+		{"Health Service Name", "statping.Storage", "", "", 2, "status:NOT_SERVING", "", true, 2, "status:NOT_SERVING"},
+		{"Unary Method", "", "grpc.health.v1.Health/Check", `{"service": ""}`, 0, "", "", true, 0, `{"status":"SERVING"}`},
+		{"Unary Method Default Status", "", "grpc.health.v1.Health/Check", `{"service": ""}`, 200, "", "", true, 0, `{"status":"SERVING"}`},
+		{"Unary Method Cancelled Status", "", "grpc.health.v1.Health/Check", `{"service": ""}`, 1, "", "", false, 0, `{"status":"SERVING"}`},
+		{"Unary Method Regex", "", "/grpc.health.v1.Health/Check", `{"service": "statping.Storage"}`, 0, "NOT_SERVING", "", true, 0, `{"status":"NOT_SERVING"}`},
+		{"JSON Assertion", "", "grpc.health.v1.Health.Check", `{"service": "statping.Storage"}`, 0, "", `[{"source":"json","property":"status","comparison":"equals","value":"SERVING"}]`, false, 0, `{"status":"NOT_SERVING"}`},
+		{"Metadata Assertion", "", "grpc.health.v1.Health/Check", "", 0, "", `[{"source":"header","property":"x-subsystem","comparison":"equals","value":"storage"}]`, true, 0, `{"status":"SERVING"}`},
+		{"Status Code", "", "grpc.health.v1.Health/Check", `{"service": "missing"}`, 0, "", "", false, 5, "unknown service"},
+		{"Expected Status Code", "", "grpc.health.v1.Health/Check", `{"service": "missing"}`, 5, "", "", true, 5, "unknown service"},
+		{"Status Code Assertion", "", "grpc.health.v1.Health/Check", `{"service": "missing"}`, 0, "", `[{"source":"status_code","comparison":"equals","value":"5"}]`, true, 5, "unknown service"},
+		{"Invalid Request", "", "grpc.health.v1.Health/Check", `{"missing": 1}`, 0, "", "", false, 0, ""},
+		{"Unknown Method", "", "grpc.health.v1.Health/Ping", "", 0, "", "", false, 0, ""},
+		{"Streaming Method", "", "grpc.health.v1.Health/Watch", "", 0, "", "", false, 0, ""},
+		{"Unknown Service", "", "statping.Missing/Check", "", 0, "", "", false, 0, ""},
+	}
+
+	for _, v := range tests {
+		t.Run(v.Name, func(t *testing.T) {
+			s := &Service{
+				Name:              v.Name,
+				Domain:            "127.0.0.1",
+				Port:              port,
+				Type:              "grpc",
+				Timeout:           2,
+				Headers:           null.NewNullString("X-Api-Key=secret"),
+				GrpcHealthCheck:   null.NewNullBool(v.Method == ""),
+				GrpcHealthService: v.HealthService,
+				GrpcMethod:        v.Method,
+				PostData:          null.NewNullString(v.Request),
+				ExpectedStatus:    v.ExpectedStatus,
+				Expected:          null.NewNullString(v.Expected),
+				Assertions:        null.NewNullString(v.Assertions),
+			}
+			s.CheckService(false)
+			assert.Equal(t, v.Online, s.Online)
+			assert.Equal(t, v.StatusCode, s.LastStatusCode)
+			assert.Equal(t, v.Response, s.LastResponse)
+		})
+	}
+
+	t.Run("Health Check Method", func(t *testing.T) {
+		s := &Service{
+			Name:            "Health Check Method",
+			Domain:          "127.0.0.1",
+			Port:            port,
+			Type:            "grpc",
+			Timeout:         2,
+			Headers:         null.NewNullString("X-Api-Key=secret"),
+			GrpcHealthCheck: null.NewNullBool(true),
+			GrpcMethod:      "grpc.health.v1.Health/Check",
+			PostData:        null.NewNullString(`{"service": ""}`),
+			ExpectedStatus:  1,
+		}
+		s.CheckService(false)
+		assert.True(t, s.Online)
+		assert.Equal(t, int(codes.OK), s.LastStatusCode)
+	})
+
+	t.Run("Missing Metadata", func(t *testing.T) {
+		s := &Service{
+			Name:       "Missing Metadata",
+			Domain:     "127.0.0.1",
+			Port:       port,
+			Type:       "grpc",
+			Timeout:    2,
+			GrpcMethod: "grpc.health.v1.Health/Check",
+		}
+		s.CheckService(false)
+		assert.False(t, s.Online)
+		assert.Equal(t, int(codes.Unauthenticated), s.LastStatusCode)
+	})
+}
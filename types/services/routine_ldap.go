@@ -0,0 +1,176 @@
+package services
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/statping-ng/statping-ng/types/metrics"
+	"github.com/statping-ng/statping-ng/utils"
+)
+
+const defaultLdapFilter = "(objectClass=*)"
+
+// ldapAddress returns the host:port of the 'ldap://' or 'ldaps://' URL of the service, and if it uses ldaps
+func (s *Service) ldapAddress() (string, bool, error) {
+	u, err := url.Parse(s.Domain)
+	if err != nil {
+		return "", false, err
+	}
+	var ldaps bool
+	port := "389"
+	switch strings.ToLower(u.Scheme) {
+	case "ldap":
+	case "ldaps":
+		ldaps, port = true, "636"
+	default:
+		return "", false, fmt.Errorf("unknown LDAP scheme '%s', use ldap:// or ldaps://", u.Scheme)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	} else if s.Port != 0 {
+		port = strconv.Itoa(s.Port)
+	}
+	return net.JoinHostPort(u.Hostname(), port), ldaps, nil
+}
+
+// ldapTLSConfig returns the TLS configuration for ldaps and StartTLS, the certificate is
+// only verified when SSL verification is enabled for the service
+func (s *Service) ldapTLSConfig(host string) *tls.Config {
+	config, err := s.LoadTLSCert()
+	if err != nil {
+		log.Errorln(err)
+	}
+	if config == nil {
+		config = &tls.Config{InsecureSkipVerify: true}
+	}
+	config = config.Clone()
+	config.ServerName = host
+	return config
+}
+
+// CheckLdap will bind to the directory of a 'ldap' service with the credentials and run the search
+// when a base DN is set. Assertions can check the 'entry_count' of the search.
+func CheckLdap(s *Service, record bool) (*Service, error) {
+	defer s.updateLastCheck()
+	timer := prometheus.NewTimer(metrics.ServiceTimer(s.Name))
+	defer timer.ObserveDuration()
+
+	assertions, err := s.ParseAssertions()
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("LDAP Assertions Error %v", err), "assertion")
+		}
+		return s, err
+	}
+
+	addr, ldaps, err := s.ldapAddress()
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("LDAP URL Error: %v", err), "connection")
+		}
+		return s, err
+	}
+
+	dnsLookup, err := dnsCheck(s)
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("Could not get IP address for LDAP service %v, %v", s.Domain, err), "lookup")
+		}
+		return s, err
+	}
+	s.PingTime = dnsLookup
+
+	timeout := time.Duration(s.Timeout) * time.Second
+	host, _, _ := net.SplitHostPort(addr)
+	tlsConfig := s.ldapTLSConfig(host)
+
+	t1 := utils.Now()
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("Dial Error: %v", err), "connection")
+		}
+		return s, err
+	}
+	if ldaps {
+		tlsConn := tls.Client(conn, tlsConfig)
+		tlsConn.SetDeadline(utils.Now().Add(timeout))
+		if err = tlsConn.Handshake(); err != nil {
+			conn.Close()
+			if record {
+				RecordFailure(s, fmt.Sprintf("LDAP TLS Error: %v", err), "tls")
+			}
+			return s, err
+		}
+		tlsConn.SetDeadline(time.Time{})
+		conn = tlsConn
+	}
+	l := ldap.NewConn(conn, ldaps)
+	l.SetTimeout(timeout)
+	l.Start()
+	defer l.Close()
+
+	if s.LdapStartTLS.Bool && !ldaps {
+		if err = l.StartTLS(tlsConfig); err != nil {
+			if record {
+				RecordFailure(s, fmt.Sprintf("LDAP StartTLS Error: %v", err), "tls")
+			}
+			return s, err
+		}
+	}
+
+	if username, password := s.headerCredentials(); username != "" {
+		if err = l.Bind(username, password); err != nil {
+			if record {
+				RecordFailure(s, fmt.Sprintf("LDAP Bind Error: %v", err), "bind")
+			}
+			return s, err
+		}
+	}
+
+	res := &assertionResponse{Info: make(map[string]string)}
+	if s.LdapBaseDN != "" {
+		filter := s.LdapFilter
+		if filter == "" {
+			filter = defaultLdapFilter
+		}
+		request := ldap.NewSearchRequest(s.LdapBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, s.Timeout, false, filter, []string{"1.1"}, nil)
+		result, err := l.Search(request)
+		if err != nil {
+			if record {
+				RecordFailure(s, fmt.Sprintf("LDAP Search Error: %v", err), "search")
+			}
+			return s, err
+		}
+		res.Info["entry_count"] = strconv.Itoa(len(result.Entries))
+		s.LastResponse = fmt.Sprintf("%d entries", len(result.Entries))
+	}
+	s.Latency = utils.Now().Sub(t1).Microseconds()
+
+	s.softFailures = nil
+	if len(assertions) > 0 {
+		res.Latency = time.Duration(s.Latency) * time.Microsecond
+		failed, soft := checkAssertions(assertions, res)
+		if len(failed) > 0 {
+			err = fmt.Errorf("%d of %d assertions failed", len(failed), len(assertions))
+			if record {
+				RecordFailure(s, fmt.Sprintf("LDAP Assertions failed: %s", strings.Join(failed, "; ")), "assertion")
+			}
+			return s, err
+		}
+		s.softFailures = soft
+	}
+
+	s.Online = true
+	if record {
+		RecordSuccess(s)
+	}
+	return s, nil
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ldapResponse encodes a LDAP result message, such as a BindResponse or SearchResultDone
+func ldapResponse(id int64, op ber.Tag, code int, message string) *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "MessageID"))
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, op, nil, "Result")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "resultCode"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message, "diagnosticMessage"))
+	packet.AppendChild(result)
+	return packet
+}
+
+// ldapTestServer starts a local directory that binds 'cn=statping,dc=example,dc=org' with the password
+// and has 3 entries below 'ou=people,dc=example,dc=org'. It supports StartTLS unless ldaps is used.
+func ldapTestServer(t *testing.T, password string, ldaps bool) int {
+	// borrow the self-signed certificate of a test server
+	certServer := httptest.NewTLSServer(nil)
+	t.Cleanup(certServer.Close)
+	tlsConfig := &tls.Config{Certificates: certServer.TLS.Certificates}
+
+	var listener net.Listener
+	var err error
+	if ldaps {
+		listener, err = tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	} else {
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	require.Nil(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	handle := func(conn net.Conn) {
+		defer func() { conn.Close() }()
+		for {
+			packet, err := ber.ReadPacket(conn)
+			if err != nil || len(packet.Children) < 2 {
+				return
+			}
+			id := packet.Children[0].Value.(int64)
+			request := packet.Children[1]
+			switch request.Tag {
+			case ldap.ApplicationBindRequest:
+				name := request.Children[1].Value.(string)
+				code := ldap.LDAPResultSuccess
+				if name != "cn=statping,dc=example,dc=org" || request.Children[2].Data.String() != password {
+					code = ldap.LDAPResultInvalidCredentials
+				}
+				conn.Write(ldapResponse(id, ldap.ApplicationBindResponse, int(code), "").Bytes())
+			case ldap.ApplicationSearchRequest:
+				base := request.Children[0].Value.(string)
+				if base != "ou=people,dc=example,dc=org" {
+					conn.Write(ldapResponse(id, ldap.ApplicationSearchResultDone, int(ldap.LDAPResultNoSuchObject), "").Bytes())
+					continue
+				}
+				for i := 1; i <= 3; i++ {
+					entry := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+					entry.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "MessageID"))
+					result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Entry")
+					result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, fmt.Sprintf("uid=user%d,%s", i, base), "objectName"))
+					result.AppendChild(ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attributes"))
+					entry.AppendChild(result)
+					conn.Write(entry.Bytes())
+				}
+				conn.Write(ldapResponse(id, ldap.ApplicationSearchResultDone, int(ldap.LDAPResultSuccess), "").Bytes())
+			case ldap.ApplicationExtendedRequest:
+				if ldaps {
+					conn.Write(ldapResponse(id, ldap.ApplicationExtendedResponse, int(ldap.LDAPResultUnavailable), "TLS already started").Bytes())
+					continue
+				}
+				conn.Write(ldapResponse(id, ldap.ApplicationExtendedResponse, int(ldap.LDAPResultSuccess), "").Bytes())
+				conn = tls.Server(conn, tlsConfig)
+			case ldap.ApplicationUnbindRequest:
+				return
+			}
+		}
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go handle(conn)
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestCheckLdap(t *testing.T) {
+	ldapPort := ldapTestServer(t, "secret", false)
+	ldapsPort := ldapTestServer(t, "secret", true)
+	bind := "Username=cn=statping,dc=example,dc=org,Password=secret"
+
+	tests := []struct {
+		Name       string
+		Domain     string
+		StartTLS   bool
+		VerifySSL  bool
+		Headers    string
+		BaseDN     string
+		Assertions string
+		Error      string
+	}{
+		{"Anonymous", fmt.Sprintf("ldap://127.0.0.1:%d", ldapPort), false, false, "", "", "", ""},
+		{"Bind", fmt.Sprintf("ldap://127.0.0.1:%d", ldapPort), false, false, bind, "", "", ""},
+		{"Wrong Password", fmt.Sprintf("ldap://127.0.0.1:%d", ldapPort), false, false, "Username=cn=statping,dc=example,dc=org,Password=expired", "", "", `LDAP Result Code 49 "Invalid Credentials": `},
+		{"Search", fmt.Sprintf("ldap://127.0.0.1:%d", ldapPort), false, false, bind, "ou=people,dc=example,dc=org", `[{"source":"entry_count","comparison":"greater_than","value":"0"}]`, ""},
+		{"Too Few Entries", fmt.Sprintf("ldap://127.0.0.1:%d", ldapPort), false, false, bind, "ou=people,dc=example,dc=org", `[{"source":"entry_count","comparison":"greater_than","value":"5"}]`, "1 of 1 assertions failed"},
+		{"Missing Base DN", fmt.Sprintf("ldap://127.0.0.1:%d", ldapPort), false, false, bind, "ou=missing,dc=example,dc=org", "", `LDAP Result Code 32 "No Such Object": `},
+		{"StartTLS", fmt.Sprintf("ldap://127.0.0.1:%d", ldapPort), true, false, bind, "ou=people,dc=example,dc=org", "", ""},
+		{"LDAPS", fmt.Sprintf("ldaps://127.0.0.1:%d", ldapsPort), false, false, bind, "ou=people,dc=example,dc=org", "", ""},
+		{"LDAPS Untrusted", fmt.Sprintf("ldaps://127.0.0.1:%d", ldapsPort), false, true, bind, "", "", "x509: certificate signed by unknown authority"},
+		{"Unknown Scheme", fmt.Sprintf("http://127.0.0.1:%d", ldapPort), false, false, "", "", "", "unknown LDAP scheme 'http', use ldap:// or ldaps://"},
+	}
+
+	for _, v := range tests {
+		t.Run(v.Name, func(t *testing.T) {
+			s := &Service{
+				Name:         "LDAP " + v.Name,
+				Domain:       v.Domain,
+				Type:         "ldap",
+				Timeout:      2,
+				VerifySSL:    null.NewNullBool(v.VerifySSL),
+				Headers:      null.NewNullString(v.Headers),
+				Assertions:   null.NewNullString(v.Assertions),
+				LdapStartTLS: null.NewNullBool(v.StartTLS),
+				LdapBaseDN:   v.BaseDN,
+			}
+			_, err := CheckLdap(s, false)
+			if v.Error == "" {
+				require.Nil(t, err)
+				assert.True(t, s.Online)
+			} else {
+				require.NotNil(t, err)
+				assert.Contains(t, err.Error(), v.Error)
+				assert.False(t, s.Online)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/statping-ng/statping-ng/types/metrics"
+	"github.com/statping-ng/statping-ng/utils"
+)
+
+const (
+	defaultMqttTopic = "statping/check"
+	// mqttSubscribeFailure is the SUBACK return code of a refused subscription
+	mqttSubscribeFailure = 0x80
+)
+
+// mqttBroker returns the broker URL for the service, using 'ssl' when TLS is enabled
+func (s *Service) mqttBroker() string {
+	scheme := "tcp"
+	if s.requiresTLS() || s.TLSCert.String != "" {
+		scheme = "ssl"
+	}
+	port := s.Port
+	if port == 0 {
+		port = 1883
+	}
+	return fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(s.Domain, strconv.Itoa(port)))
+}
+
+// isMqttAuthError returns true if the broker refused the connection because of the credentials
+func isMqttAuthError(err error) bool {
+	return err == packets.ErrorRefusedBadUsernameOrPassword || err == packets.ErrorRefusedNotAuthorised
+}
+
+// CheckMqtt will subscribe to the topic of a 'mqtt' service, publish a unique payload and
+// wait for the broker to deliver it back. The latency is the publish/subscribe round-trip.
+func CheckMqtt(s *Service, record bool) (*Service, error) {
+	defer s.updateLastCheck()
+	timer := prometheus.NewTimer(metrics.ServiceTimer(s.Name))
+	defer timer.ObserveDuration()
+
+	dnsLookup, err := dnsCheck(s)
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("Could not get IP address for MQTT service %v, %v", s.Domain, err), "lookup")
+		}
+		return s, err
+	}
+	s.PingTime = dnsLookup
+
+	tlsConfig, err := s.LoadTLSCert()
+	if err != nil {
+		log.Errorln(err)
+	}
+	timeout := time.Duration(s.Timeout) * time.Second
+	topic := s.MqttTopic
+	if topic == "" {
+		topic = defaultMqttTopic
+	}
+
+	username, password := s.headerCredentials()
+	opts := mqtt.NewClientOptions().
+		AddBroker(s.mqttBroker()).
+		SetClientID(fmt.Sprintf("statping-%d-%s", s.Id, utils.RandomString(8))).
+		SetUsername(username).
+		SetPassword(password).
+		SetTLSConfig(tlsConfig).
+		SetCleanSession(true).
+		SetAutoReconnect(false).
+		SetConnectTimeout(timeout).
+		SetWriteTimeout(timeout)
+
+	client := mqtt.NewClient(opts)
+	token := client.Connect()
+	if !token.WaitTimeout(timeout) {
+		err = fmt.Errorf("timed out after %v", timeout)
+	} else {
+		err = token.Error()
+	}
+	if err != nil {
+		if record {
+			if isMqttAuthError(err) {
+				RecordFailure(s, fmt.Sprintf("MQTT Authentication Error: %v", err), "authentication")
+			} else {
+				RecordFailure(s, fmt.Sprintf("MQTT Connection Error: %v", err), "connection")
+			}
+		}
+		return s, err
+	}
+	defer client.Disconnect(250)
+
+	payload := fmt.Sprintf("statping-%d-%s", utils.Now().UnixNano(), utils.RandomString(8))
+	received := make(chan time.Time, 1)
+	subscribe := client.Subscribe(topic, 1, func(_ mqtt.Client, msg mqtt.Message) {
+		if string(msg.Payload()) == payload {
+			select {
+			case received <- utils.Now():
+			default:
+			}
+		}
+	})
+	if !subscribe.WaitTimeout(timeout) {
+		err = fmt.Errorf("timed out after %v", timeout)
+	} else if err = subscribe.Error(); err == nil {
+		if qos, ok := subscribe.(*mqtt.SubscribeToken).Result()[topic]; ok && qos == mqttSubscribeFailure {
+			err = fmt.Errorf("broker refused the subscription to '%s'", topic)
+		}
+	}
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("MQTT Subscribe Error: %v", err), "subscribe")
+		}
+		return s, err
+	}
+
+	t1 := utils.Now()
+	publish := client.Publish(topic, 1, false, payload)
+	if !publish.WaitTimeout(timeout) {
+		err = fmt.Errorf("publish timed out after %v", timeout)
+	} else {
+		err = publish.Error()
+	}
+	if err == nil {
+		select {
+		case at := <-received:
+			s.Latency = at.Sub(t1).Microseconds()
+		case <-time.After(timeout - utils.Now().Sub(t1)):
+			err = fmt.Errorf("message was not delivered within %v", timeout)
+		}
+	}
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("MQTT Delivery Error: %v", err), "delivery")
+		}
+		return s, err
+	}
+
+	s.LastResponse = payload
+	s.Online = true
+	if record {
+		RecordSuccess(s)
+	}
+	return s, nil
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mqttTestBroker starts a local MQTT broker that requires the password, refuses subscriptions
+// to 'denied' and accepts but never delivers messages published to 'blackhole'
+func mqttTestBroker(t *testing.T, password string) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	var mu sync.Mutex
+	subscribers := make(map[string][]net.Conn)
+
+	handle := func(conn net.Conn) {
+		defer conn.Close()
+		for {
+			packet, err := packets.ReadPacket(conn)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			switch p := packet.(type) {
+			case *packets.ConnectPacket:
+				ack := packets.NewControlPacket(packets.Connack).(*packets.ConnackPacket)
+				if string(p.Password) != password {
+					ack.ReturnCode = packets.ErrRefusedBadUsernameOrPassword
+				}
+				ack.Write(conn)
+			case *packets.SubscribePacket:
+				ack := packets.NewControlPacket(packets.Suback).(*packets.SubackPacket)
+				ack.MessageID = p.MessageID
+				for i, topic := range p.Topics {
+					if topic == "denied" {
+						ack.ReturnCodes = append(ack.ReturnCodes, mqttSubscribeFailure)
+						continue
+					}
+					subscribers[topic] = append(subscribers[topic], conn)
+					ack.ReturnCodes = append(ack.ReturnCodes, p.Qoss[i])
+				}
+				ack.Write(conn)
+			case *packets.PublishPacket:
+				if p.Qos > 0 {
+					ack := packets.NewControlPacket(packets.Puback).(*packets.PubackPacket)
+					ack.MessageID = p.MessageID
+					ack.Write(conn)
+				}
+				if p.TopicName != "blackhole" {
+					for _, sub := range subscribers[p.TopicName] {
+						msg := packets.NewControlPacket(packets.Publish).(*packets.PublishPacket)
+						msg.TopicName = p.TopicName
+						msg.Payload = p.Payload
+						msg.Write(sub)
+					}
+				}
+			case *packets.PingreqPacket:
+				packets.NewControlPacket(packets.Pingresp).Write(conn)
+			case *packets.DisconnectPacket:
+				mu.Unlock()
+				return
+			}
+			mu.Unlock()
+		}
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go handle(conn)
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestCheckMqtt(t *testing.T) {
+	port := mqttTestBroker(t, "secret")
+
+	tests := []struct {
+		Name    string
+		Headers string
+		Topic   string
+		Error   string
+	}{
+		{"Round Trip", "Username=statping,Password=secret", "", ""},
+		{"Custom Topic", "Username=statping,Password=secret", "devices/health", ""},
+		{"Wrong Password", "Username=statping,Password=wrong", "", "bad user name or password"},
+		{"Subscribe Refused", "Username=statping,Password=secret", "denied", "broker refused the subscription to 'denied'"},
+		{"Not Delivered", "Username=statping,Password=secret", "blackhole", "message was not delivered within 1s"},
+	}
+
+	for _, v := range tests {
+		t.Run(v.Name, func(t *testing.T) {
+			s := &Service{
+				Name:      "MQTT " + v.Name,
+				Domain:    "127.0.0.1",
+				Port:      port,
+				Type:      "mqtt",
+				Timeout:   1,
+				Headers:   null.NewNullString(v.Headers),
+				MqttTopic: v.Topic,
+			}
+			_, err := CheckMqtt(s, false)
+			if v.Error == "" {
+				require.Nil(t, err)
+				assert.True(t, s.Online)
+				assert.Greater(t, s.Latency, int64(0))
+			} else {
+				require.NotNil(t, err)
+				assert.Equal(t, v.Error, err.Error())
+				assert.False(t, s.Online)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/statping-ng/statping-ng/types/metrics"
+	"github.com/statping-ng/statping-ng/utils"
+)
+
+// CheckRedis will check a Redis service with PING, and run INFO for the assertions
+func CheckRedis(s *Service, record bool) (*Service, error) {
+	defer s.updateLastCheck()
+	timer := prometheus.NewTimer(metrics.ServiceTimer(s.Name))
+	defer timer.ObserveDuration()
+
+	assertions, err := s.ParseAssertions()
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("Redis Assertions Error %v", err), "assertion")
+		}
+		return s, err
+	}
+
+	dnsLookup, err := dnsCheck(s)
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("Could not get IP address for %s service %v, %v", strings.ToUpper(s.Type), s.Domain, err), "lookup")
+		}
+		return s, err
+	}
+	s.PingTime = dnsLookup
+	t1 := utils.Now()
+	domain := fmt.Sprintf("%v", s.Domain)
+	if s.Port != 0 {
+		domain = fmt.Sprintf("%v:%v", s.Domain, s.Port)
+		if isIPv6(s.Domain) {
+			domain = fmt.Sprintf("[%v]:%v", s.Domain, s.Port)
+		}
+	}
+
+	tlsConfig, err := s.LoadTLSCert()
+	if err != nil {
+		log.Errorln(err)
+	}
+
+	dialer := &net.Dialer{
+		KeepAlive: time.Duration(s.Timeout) * time.Second,
+		Timeout:   time.Duration(s.Timeout) * time.Second,
+	}
+	var conn net.Conn
+	if s.requiresTLS() || s.TLSCert.String != "" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", domain, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", domain)
+	}
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("Dial Error: %v", err), "connection")
+		}
+		return s, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(utils.Now().Add(time.Duration(s.Timeout) * time.Second))
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+
+	// Auth
+	if username, password := s.headerCredentials(); password != "" {
+		args := []string{"AUTH", password}
+		if username != "" {
+			args = []string{"AUTH", username, password}
+		}
+		if _, err = redisDo(rw, args...); err != nil {
+			if record {
+				RecordFailure(s, fmt.Sprintf("%s Authentication Error: %v", strings.ToUpper(s.Type), err), "authentication")
+			}
+			return s, err
+		}
+	}
+
+	pong, err := redisDo(rw, "PING")
+	if err == nil && pong != "PONG" {
+		err = fmt.Errorf("unexpected PING reply '%s'", pong)
+	}
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("%s PING Error: %v", strings.ToUpper(s.Type), err), s.Type)
+		}
+		return s, err
+	}
+	s.Latency = utils.Now().Sub(t1).Microseconds()
+	s.LastResponse = pong
+
+	s.softFailures = nil
+	if len(assertions) > 0 {
+		res := &assertionResponse{Latency: time.Duration(s.Latency) * time.Microsecond}
+		for _, a := range assertions {
+			if a.Source != "response_time" {
+				info, err := redisDo(rw, "INFO")
+				if err != nil {
+					if record {
+						RecordFailure(s, fmt.Sprintf("%s INFO Error: %v", strings.ToUpper(s.Type), err), s.Type)
+					}
+					return s, err
+				}
+				res.Info = parseRedisInfo(info)
+				break
+			}
+		}
+		failed, soft := checkAssertions(assertions, res)
+		if len(failed) > 0 {
+			err = fmt.Errorf("%d of %d assertions failed", len(failed), len(assertions))
+			if record {
+				RecordFailure(s, fmt.Sprintf("Redis Assertions failed: %s", strings.Join(failed, "; ")), "assertion")
+			}
+			return s, err
+		}
+		s.softFailures = soft
+	}
+
+	s.Online = true
+	if record {
+		RecordSuccess(s)
+	}
+	return s, nil
+}
+
+// redisCommand writes a command as a RESP array of bulk strings
+func redisCommand(w io.Writer, args ...string) error {
+	cmd := fmt.Sprintf("*%d\r\n", len(args))
+	for _, arg := range args {
+		cmd += fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err := io.WriteString(w, cmd)
+	return err
+}
+
+// redisReply reads a simple string, error, integer or bulk string reply. Error replies are returned as errors.
+func redisReply(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", fmt.Errorf("empty reply")
+	}
+	switch line[0] {
+	case '+', ':':
+		return line[1:], nil
+	case '-':
+		return "", fmt.Errorf("%s", line[1:])
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return "", fmt.Errorf("invalid bulk reply '%s'", line)
+		}
+		if size < 0 {
+			return "", nil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return "", err
+		}
+		return string(buf[:size]), nil
+	}
+	return "", fmt.Errorf("unexpected reply '%s'", line)
+}
+
+// redisDo sends a command and returns its reply
+func redisDo(rw *bufio.ReadWriter, args ...string) (string, error) {
+	if err := redisCommand(rw, args...); err != nil {
+		return "", err
+	}
+	if err := rw.Flush(); err != nil {
+		return "", err
+	}
+	return redisReply(rw.Reader)
+}
+
+// parseRedisInfo returns the fields of the INFO reply. It adds 'role' as 'master' or 'replica',
+// 'connected_replicas' and 'used_memory_percent' of maxmemory, or of the system memory without a limit.
+func parseRedisInfo(info string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) == 2 {
+			fields[parts[0]] = parts[1]
+		}
+	}
+	if fields["role"] == "slave" {
+		fields["role"] = "replica"
+	}
+	if replicas, ok := fields["connected_slaves"]; ok {
+		fields["connected_replicas"] = replicas
+	}
+	used, err := strconv.ParseFloat(fields["used_memory"], 64)
+	if err == nil {
+		limit, _ := strconv.ParseFloat(fields["maxmemory"], 64)
+		if limit == 0 {
+			limit, _ = strconv.ParseFloat(fields["total_system_memory"], 64)
+		}
+		if limit > 0 {
+			fields["used_memory_percent"] = strconv.FormatFloat(used/limit*100, 'f', 2, 64)
+		}
+	}
+	return fields
+}
+
+// redisKeyspace returns the number of keys of a database from the keyspace section, such as 'keys=12,expires=0'
+func redisKeyspace(fields map[string]string, db string) (string, bool) {
+	for _, v := range strings.Split(fields[db], ",") {
+		if strings.HasPrefix(v, "keys=") {
+			return strings.TrimPrefix(v, "keys="), true
+		}
+	}
+	return "", false
+}
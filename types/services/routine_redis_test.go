@@ -0,0 +1,139 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const redisTestInfo = "# Server\r\nredis_version:7.0.11\r\n\r\n# Memory\r\nused_memory:314572800\r\nmaxmemory:1073741824\r\n\r\n" +
+	"# Replication\r\nrole:slave\r\nmaster_link_status:up\r\nconnected_slaves:0\r\n\r\n# Keyspace\r\ndb0:keys=42,expires=3,avg_ttl=0\r\n"
+
+// redisServer starts a local server that answers AUTH, PING and INFO like a Redis replica
+func redisServer(t *testing.T, password string) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				authed := password == ""
+				for {
+					args, err := readRedisCommand(r)
+					if err != nil {
+						return
+					}
+					switch strings.ToUpper(args[0]) {
+					case "AUTH":
+						if args[len(args)-1] != password {
+							io.WriteString(conn, "-WRONGPASS invalid username-password pair\r\n")
+							continue
+						}
+						authed = true
+						io.WriteString(conn, "+OK\r\n")
+					case "PING", "INFO":
+						if !authed {
+							io.WriteString(conn, "-NOAUTH Authentication required.\r\n")
+						} else if args[0] == "PING" {
+							io.WriteString(conn, "+PONG\r\n")
+						} else {
+							fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(redisTestInfo), redisTestInfo)
+						}
+					}
+				}
+			}(conn)
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// readRedisCommand reads a RESP array of bulk strings
+func readRedisCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		if args[i], err = redisReply(r); err != nil {
+			return nil, err
+		}
+	}
+	return args, nil
+}
+
+func TestCheckRedis(t *testing.T) {
+	port := redisServer(t, "secret")
+
+	tests := []struct {
+		Name       string
+		Headers    string
+		Assertions string
+		Online     bool
+	}{
+		{"Ping", "Password=secret", "", true},
+		{"No Auth", "", "", false},
+		{"Wrong Password", "Username=default,Password=wrong", "", false},
+		{"Replica", "Password=secret", `[{"source":"role","comparison":"equals","value":"replica"},{"source":"master_link_status","comparison":"equals","value":"up"}]`, true},
+		{"Master", "Password=secret", `[{"source":"role","comparison":"equals","value":"master"}]`, false},
+		{"Replicas", "Password=secret", `[{"source":"connected_replicas","comparison":"greater_than","value":"0"}]`, false},
+		{"Memory", "Password=secret", `[{"source":"used_memory_percent","comparison":"less_than","value":"50"}]`, true},
+		{"Keyspace", "Password=secret", `[{"source":"keyspace","property":"db0","comparison":"greater_than","value":"10"},{"source":"keyspace","property":"db1","comparison":"not_exists"}]`, true},
+		{"Info", "Password=secret", `[{"source":"info","property":"redis_version","comparison":"matches","value":"^7\\."}]`, true},
+		{"HTTP Assertion", "Password=secret", `[{"source":"status_code","comparison":"equals","value":"200"}]`, false},
+	}
+
+	for _, v := range tests {
+		t.Run(v.Name, func(t *testing.T) {
+			s := &Service{
+				Name:       "Redis " + v.Name,
+				Domain:     "127.0.0.1",
+				Port:       port,
+				Type:       "redis",
+				Timeout:    2,
+				Headers:    null.NewNullString(v.Headers),
+				Assertions: null.NewNullString(v.Assertions),
+			}
+			_, err := CheckRedis(s, false)
+			if v.Online {
+				assert.Nil(t, err)
+				assert.True(t, s.Online)
+			} else {
+				assert.NotNil(t, err)
+				assert.False(t, s.Online)
+			}
+		})
+	}
+}
+
+func TestParseRedisInfo(t *testing.T) {
+	fields := parseRedisInfo(redisTestInfo)
+	assert.Equal(t, "replica", fields["role"])
+	assert.Equal(t, "0", fields["connected_replicas"])
+	assert.Equal(t, "29.30", fields["used_memory_percent"])
+
+	keys, ok := redisKeyspace(fields, "db0")
+	assert.True(t, ok)
+	assert.Equal(t, "42", keys)
+	_, ok = redisKeyspace(fields, "db1")
+	assert.False(t, ok)
+}
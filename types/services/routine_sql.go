@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/statping-ng/statping-ng/types/errors"
+	"github.com/statping-ng/statping-ng/types/metrics"
+	"github.com/statping-ng/statping-ng/utils"
+)
+
+// sqlDrivers are the database/sql drivers that can be used by 'sql' services
+var sqlDrivers = map[string]bool{
+	"postgres": true,
+	"mysql":    true,
+	"sqlite3":  true,
+}
+
+// sqlResult contains the row count and the first column of the first row returned by the query
+type sqlResult struct {
+	Rows     int
+	Value    string
+	HasValue bool
+}
+
+// isSqlAuthError returns true if the database rejected the credentials or access to the database
+func isSqlAuthError(err error) bool {
+	switch e := err.(type) {
+	case *pq.Error:
+		// class 28 is 'Invalid Authorization Specification'
+		return e.Code.Class() == "28"
+	case *mysql.MySQLError:
+		// ER_DBACCESS_DENIED_ERROR and ER_ACCESS_DENIED_ERROR
+		return e.Number == 1044 || e.Number == 1045
+	}
+	return false
+}
+
+// sqlQuery runs the query of the service in a read-only transaction on conn
+func sqlQuery(ctx context.Context, s *Service, conn *sql.Conn) (*sqlResult, error) {
+	if s.SqlDriver == "sqlite3" {
+		// the sqlite3 driver ignores read-only transactions
+		if _, err := conn.ExecContext(ctx, "PRAGMA query_only = ON"); err != nil {
+			return nil, err
+		}
+	}
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, s.SqlQuery.String)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &sqlResult{}
+	for rows.Next() {
+		result.Rows++
+		if result.Rows > 1 || len(columns) == 0 {
+			continue
+		}
+		values := make([]interface{}, len(columns))
+		first := new(sql.NullString)
+		values[0] = first
+		for i := 1; i < len(columns); i++ {
+			values[i] = new(sql.RawBytes)
+		}
+		if err := rows.Scan(values...); err != nil {
+			return nil, err
+		}
+		result.Value, result.HasValue = first.String, first.Valid
+	}
+	return result, rows.Err()
+}
+
+// CheckSql will connect to the database of a 'sql' service with the DSN in the domain and run its query
+func CheckSql(s *Service, record bool) (*Service, error) {
+	defer s.updateLastCheck()
+	timer := prometheus.NewTimer(metrics.ServiceTimer(s.Name))
+	defer timer.ObserveDuration()
+
+	assertions, err := s.ParseAssertions()
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("SQL Assertions Error %v", err), "assertion")
+		}
+		return s, err
+	}
+	if !sqlDrivers[s.SqlDriver] {
+		err = fmt.Errorf("unknown SQL driver '%s'", s.SqlDriver)
+		if record {
+			RecordFailure(s, fmt.Sprintf("SQL Error %v", err), "connection")
+		}
+		return s, err
+	}
+	if strings.TrimSpace(s.SqlQuery.String) == "" {
+		err = errors.New("service has no SQL query")
+		if record {
+			RecordFailure(s, fmt.Sprintf("SQL Error %v", err), "query")
+		}
+		return s, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout)*time.Second)
+	defer cancel()
+
+	t1 := utils.Now()
+	db, err := sql.Open(s.SqlDriver, s.Domain)
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("SQL Connection Error %v", err), "connection")
+		}
+		return s, err
+	}
+	defer db.Close()
+
+	conn, err := db.Conn(ctx)
+	if err == nil {
+		err = conn.PingContext(ctx)
+	}
+	if err != nil {
+		if record {
+			if isSqlAuthError(err) {
+				RecordFailure(s, fmt.Sprintf("SQL Authentication Error %v", err), "authentication")
+			} else {
+				RecordFailure(s, fmt.Sprintf("SQL Connection Error %v", err), "connection")
+			}
+		}
+		return s, err
+	}
+	defer conn.Close()
+	s.PingTime = utils.Now().Sub(t1).Microseconds()
+
+	t2 := utils.Now()
+	result, err := sqlQuery(ctx, s, conn)
+	s.Latency = utils.Now().Sub(t2).Microseconds()
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("SQL Query Error %v", err), "query")
+		}
+		return s, err
+	}
+	s.LastResponse = result.Value
+
+	s.softFailures = nil
+	if len(assertions) > 0 {
+		failed, soft := checkAssertions(assertions, &assertionResponse{
+			Rows:     result.Rows,
+			Value:    result.Value,
+			HasValue: result.HasValue,
+			Latency:  time.Duration(s.Latency) * time.Microsecond,
+		})
+		if len(failed) > 0 {
+			err = fmt.Errorf("%d of %d assertions failed", len(failed), len(assertions))
+			if record {
+				RecordFailure(s, fmt.Sprintf("SQL Assertions failed: %s", strings.Join(failed, "; ")), "assertion")
+			}
+			return s, err
+		}
+		s.softFailures = soft
+	}
+
+	s.Online = true
+	if record {
+		RecordSuccess(s)
+	}
+	return s, nil
+}
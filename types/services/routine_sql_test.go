@@ -0,0 +1,81 @@
+package services
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSql(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "monitor.db")
+	db, err := sql.Open("sqlite3", dsn)
+	require.Nil(t, err)
+	_, err = db.Exec(`CREATE TABLE jobs (id INTEGER PRIMARY KEY, state TEXT, lag INTEGER);
+		INSERT INTO jobs (state, lag) VALUES ('queued', 4), ('queued', 12), ('done', 0);`)
+	require.Nil(t, err)
+	require.Nil(t, db.Close())
+
+	tests := []struct {
+		Name       string
+		Query      string
+		Assertions string
+		Online     bool
+	}{
+		{"Query Only", "SELECT 1", "", true},
+		{"Row Count", "SELECT id FROM jobs WHERE state = 'queued'", `[{"source":"row_count","comparison":"equals","value":"2"}]`, true},
+		{"Row Count Too High", "SELECT id FROM jobs", `[{"source":"row_count","comparison":"less_than","value":"3"}]`, false},
+		{"First Value", "SELECT MAX(lag) FROM jobs", `[{"source":"value","comparison":"less_than","value":"30"}]`, true},
+		{"First Value Too High", "SELECT MAX(lag) FROM jobs", `[{"source":"value","comparison":"less_than","value":"10"}]`, false},
+		{"Null Value", "SELECT NULL", `[{"source":"value","comparison":"exists"}]`, false},
+		{"HTTP Assertion", "SELECT 1", `[{"source":"status_code","comparison":"equals","value":"200"}]`, false},
+		{"Invalid Query", "SELECT * FROM missing", "", false},
+		{"Read Only", "DELETE FROM jobs", "", false},
+	}
+
+	for _, v := range tests {
+		t.Run(v.Name, func(t *testing.T) {
+			s := &Service{
+				Name:       "SQL " + v.Name,
+				Domain:     dsn,
+				Type:       "sql",
+				SqlDriver:  "sqlite3",
+				SqlQuery:   null.NewNullString(v.Query),
+				Assertions: null.NewNullString(v.Assertions),
+				Timeout:    2,
+			}
+			_, err := CheckSql(s, false)
+			if v.Online {
+				assert.Nil(t, err)
+				assert.True(t, s.Online)
+			} else {
+				assert.NotNil(t, err)
+				assert.False(t, s.Online)
+			}
+		})
+	}
+
+	db, err = sql.Open("sqlite3", dsn)
+	require.Nil(t, err)
+	defer db.Close()
+	var count int
+	require.Nil(t, db.QueryRow("SELECT COUNT(*) FROM jobs").Scan(&count))
+	assert.Equal(t, 3, count)
+
+	s := &Service{Domain: dsn, Type: "sql", SqlDriver: "oracle", SqlQuery: null.NewNullString("SELECT 1"), Timeout: 2}
+	_, err = CheckSql(s, false)
+	assert.EqualError(t, err, "unknown SQL driver 'oracle'")
+}
+
+func TestSqlAuthError(t *testing.T) {
+	assert.True(t, isSqlAuthError(&pq.Error{Code: "28P01"}))
+	assert.False(t, isSqlAuthError(&pq.Error{Code: "42P01"}))
+	assert.True(t, isSqlAuthError(&mysql.MySQLError{Number: 1045}))
+	assert.False(t, isSqlAuthError(&mysql.MySQLError{Number: 1146}))
+	assert.False(t, isSqlAuthError(sql.ErrConnDone))
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/statping-ng/statping-ng/types/metrics"
+	"github.com/statping-ng/statping-ng/utils"
+	"golang.org/x/crypto/ssh"
+)
+
+// sshFingerprintMatches returns true if the host key matches the pinned fingerprint, either
+// in the 'SHA256:...' format of ssh-keygen or as a legacy MD5 fingerprint such as 'MD5:16:27:ac:...'
+func sshFingerprintMatches(key ssh.PublicKey, pinned string) bool {
+	pinned = strings.TrimSpace(pinned)
+	if strings.HasPrefix(pinned, "SHA256:") {
+		return strings.TrimRight(pinned, "=") == ssh.FingerprintSHA256(key)
+	}
+	return strings.EqualFold(strings.TrimPrefix(pinned, "MD5:"), ssh.FingerprintLegacyMD5(key))
+}
+
+// sshAuthMethods returns the password and private key authentication methods of the service.
+// The password of the credentials is used as the passphrase of an encrypted private key.
+func (s *Service) sshAuthMethods() (string, []ssh.AuthMethod, error) {
+	username, password := s.headerCredentials()
+	var methods []ssh.AuthMethod
+	if s.SshPrivateKey.String != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(s.SshPrivateKey.String))
+		if _, ok := err.(*ssh.PassphraseMissingError); ok && password != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(s.SshPrivateKey.String), []byte(password))
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid private key: %v", err)
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	} else if password != "" {
+		methods = append(methods, ssh.Password(password))
+	}
+	return username, methods, nil
+}
+
+// sshAuthExhausted returns true if the handshake failed because the server did not accept any of
+// the authentication methods, which means the server is reachable when no credentials are set
+func sshAuthExhausted(err error) bool {
+	return strings.Contains(err.Error(), "unable to authenticate")
+}
+
+// CheckSsh will perform the SSH handshake of a 'ssh' service and verify the host key against the pinned
+// fingerprint. With credentials it authenticates, and runs the command which must exit with status 0
+// and match the expected output. A command requires credentials.
+func CheckSsh(s *Service, record bool) (*Service, error) {
+	defer s.updateLastCheck()
+	timer := prometheus.NewTimer(metrics.ServiceTimer(s.Name))
+	defer timer.ObserveDuration()
+
+	username, auth, err := s.sshAuthMethods()
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("SSH Authentication Error: %v", err), "authentication")
+		}
+		return s, err
+	}
+	if s.SshCommand.String != "" && len(auth) == 0 {
+		err = fmt.Errorf("command '%s' requires a password or private key", s.SshCommand.String)
+		if record {
+			RecordFailure(s, fmt.Sprintf("SSH Authentication Error: %v", err), "authentication")
+		}
+		return s, err
+	}
+
+	dnsLookup, err := dnsCheck(s)
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("Could not get IP address for SSH service %v, %v", s.Domain, err), "lookup")
+		}
+		return s, err
+	}
+	s.PingTime = dnsLookup
+
+	port := s.Port
+	if port == 0 {
+		port = 22
+	}
+	addr := net.JoinHostPort(s.Domain, strconv.Itoa(port))
+	timeout := time.Duration(s.Timeout) * time.Second
+
+	t1 := utils.Now()
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("Dial Error: %v", err), "connection")
+		}
+		return s, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(utils.Now().Add(timeout))
+
+	var fingerprint string
+	var hostKeyErr error
+	config := &ssh.ClientConfig{
+		User: username,
+		Auth: auth,
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			fingerprint = ssh.FingerprintSHA256(key)
+			if s.SshFingerprint != "" && !sshFingerprintMatches(key, s.SshFingerprint) {
+				hostKeyErr = fmt.Errorf("host key %s %s does not match the pinned fingerprint %s", key.Type(), fingerprint, s.SshFingerprint)
+				return hostKeyErr
+			}
+			return nil
+		},
+		Timeout: timeout,
+	}
+	if config.User == "" {
+		config.User = "statping"
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		switch {
+		case hostKeyErr != nil:
+			err = hostKeyErr
+			if record {
+				RecordFailure(s, fmt.Sprintf("SSH Host Key Error: %v", err), "host_key")
+			}
+			return s, err
+		case fingerprint == "" || (len(auth) == 0 && !sshAuthExhausted(err)):
+			if record {
+				RecordFailure(s, fmt.Sprintf("SSH Handshake Error: %v", err), "connection")
+			}
+			return s, err
+		case len(auth) > 0:
+			if record {
+				RecordFailure(s, fmt.Sprintf("SSH Authentication Error: %v", err), "authentication")
+			}
+			return s, err
+		}
+		// without credentials only the handshake and the host key are checked
+		s.Latency = utils.Now().Sub(t1).Microseconds()
+		s.LastResponse = fingerprint
+		s.Online = true
+		if record {
+			RecordSuccess(s)
+		}
+		return s, nil
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+	defer client.Close()
+	s.Latency = utils.Now().Sub(t1).Microseconds()
+	s.LastResponse = fingerprint
+
+	if s.SshCommand.String != "" {
+		session, err := client.NewSession()
+		if err != nil {
+			if record {
+				RecordFailure(s, fmt.Sprintf("SSH Session Error: %v", err), "command")
+			}
+			return s, err
+		}
+		defer session.Close()
+		output, err := session.CombinedOutput(s.SshCommand.String)
+		s.LastResponse = strings.TrimSpace(string(output))
+		if err != nil {
+			if exitErr, ok := err.(*ssh.ExitError); ok {
+				err = fmt.Errorf("command exited with status %d", exitErr.ExitStatus())
+			}
+			if record {
+				RecordFailure(s, fmt.Sprintf("SSH Command Error: %v, %v", err, s.LastResponse), "command")
+			}
+			return s, err
+		}
+		if s.Expected.String != "" {
+			match, err := regexp.MatchString(s.Expected.String, string(output))
+			if err == nil && !match {
+				err = fmt.Errorf("command output did not match '%v'", s.Expected.String)
+			}
+			if err != nil {
+				if record {
+					RecordFailure(s, fmt.Sprintf("SSH Command Output Error: %v", err), "expected")
+				}
+				return s, err
+			}
+		}
+	}
+
+	s.Online = true
+	if record {
+		RecordSuccess(s)
+	}
+	return s, nil
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"net"
+	"testing"
+
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// sshTestServer starts a local SSH server that accepts the password and the public key.
+// The 'uptime' command succeeds, every other command exits with status 1.
+func sshTestServer(t *testing.T, hostKey ssh.Signer, password string, authorized ssh.PublicKey) int {
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if string(pass) == password {
+				return nil, nil
+			}
+			return nil, ssh.ErrNoAuth
+		},
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) == string(authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, ssh.ErrNoAuth
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	handle := func(conn net.Conn) {
+		defer conn.Close()
+		_, chans, reqs, err := ssh.NewServerConn(conn, config)
+		if err != nil {
+			return
+		}
+		go ssh.DiscardRequests(reqs)
+		for newChannel := range chans {
+			if newChannel.ChannelType() != "session" {
+				newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+				continue
+			}
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				return
+			}
+			for req := range requests {
+				if req.Type != "exec" {
+					req.Reply(false, nil)
+					continue
+				}
+				req.Reply(true, nil)
+				command := string(req.Payload[4:])
+				status := make([]byte, 4)
+				if command == "uptime" {
+					channel.Write([]byte(" 10:14:02 up 3 days,  2:01,  load average: 0.08, 0.03, 0.01\n"))
+				} else {
+					channel.Stderr().Write([]byte(command + ": command not found\n"))
+					binary.BigEndian.PutUint32(status, 1)
+				}
+				channel.SendRequest("exit-status", false, status)
+				channel.Close()
+			}
+		}
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go handle(conn)
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func sshTestKey(t *testing.T) (ssh.Signer, string) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.Nil(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.Nil(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.Nil(t, err)
+	return signer, string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func TestCheckSsh(t *testing.T) {
+	hostKey, _ := sshTestKey(t)
+	clientKey, clientPem := sshTestKey(t)
+	otherKey, otherPem := sshTestKey(t)
+	port := sshTestServer(t, hostKey, "secret", clientKey.PublicKey())
+	fingerprint := ssh.FingerprintSHA256(hostKey.PublicKey())
+
+	tests := []struct {
+		Name        string
+		Headers     string
+		PrivateKey  string
+		Fingerprint string
+		Command     string
+		Expected    string
+		Error       string
+	}{
+		{"Handshake", "", "", fingerprint, "", "", ""},
+		{"MD5 Fingerprint", "", "", "MD5:" + ssh.FingerprintLegacyMD5(hostKey.PublicKey()), "", "", ""},
+		{"Host Key Changed", "", "", ssh.FingerprintSHA256(otherKey.PublicKey()), "", "", "host key ssh-ed25519 " + fingerprint + " does not match the pinned fingerprint " + ssh.FingerprintSHA256(otherKey.PublicKey())},
+		{"Password", "Username=statping,Password=secret", "", fingerprint, "", "", ""},
+		{"Wrong Password", "Username=statping,Password=wrong", "", fingerprint, "", "", "ssh: handshake failed: ssh: unable to authenticate, attempted methods [none password], no supported methods remain"},
+		{"Private Key", "Username=statping", clientPem, "", "uptime", "load average", ""},
+		{"Unknown Private Key", "Username=statping", otherPem, "", "", "", "ssh: handshake failed: ssh: unable to authenticate, attempted methods [none publickey], no supported methods remain"},
+		{"Invalid Private Key", "Username=statping", "not a key", "", "", "", "invalid private key: ssh: no key found"},
+		{"Command Failed", "Username=statping,Password=secret", "", "", "reboot", "", "command exited with status 1"},
+		{"Output Mismatch", "Username=statping,Password=secret", "", "", "uptime", "up [0-9]+ min", "command output did not match 'up [0-9]+ min'"},
+		{"Command Without Credentials", "", "", "", "uptime", "", "command 'uptime' requires a password or private key"},
+	}
+
+	for _, v := range tests {
+		t.Run(v.Name, func(t *testing.T) {
+			s := &Service{
+				Name:           "SSH " + v.Name,
+				Domain:         "127.0.0.1",
+				Port:           port,
+				Type:           "ssh",
+				Timeout:        2,
+				Headers:        null.NewNullString(v.Headers),
+				SshPrivateKey:  null.NewNullString(v.PrivateKey),
+				SshFingerprint: v.Fingerprint,
+				SshCommand:     null.NewNullString(v.Command),
+				Expected:       null.NewNullString(v.Expected),
+			}
+			_, err := CheckSsh(s, false)
+			if v.Error == "" {
+				require.Nil(t, err)
+				assert.True(t, s.Online)
+			} else {
+				require.NotNil(t, err)
+				assert.Equal(t, v.Error, err.Error())
+				assert.False(t, s.Online)
+			}
+		})
+	}
+
+	t.Run("Disconnect After Key Exchange", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.Nil(t, err)
+		defer listener.Close()
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				// the server closes the connection on the first authentication attempt
+				config := &ssh.ServerConfig{
+					PasswordCallback: func(ssh.ConnMetadata, []byte) (*ssh.Permissions, error) {
+						return nil, ssh.ErrNoAuth
+					},
+					AuthLogCallback: func(ssh.ConnMetadata, string, error) { conn.Close() },
+				}
+				config.AddHostKey(hostKey)
+				go ssh.NewServerConn(conn, config)
+			}
+		}()
+
+		s := &Service{
+			Name:    "SSH Disconnect",
+			Domain:  "127.0.0.1",
+			Port:    listener.Addr().(*net.TCPAddr).Port,
+			Type:    "ssh",
+			Timeout: 2,
+		}
+		_, err = CheckSsh(s, false)
+		require.NotNil(t, err)
+		assert.False(t, s.Online)
+		assert.NotContains(t, err.Error(), "unable to authenticate")
+	})
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/statping-ng/statping-ng/types/errors"
+	"github.com/statping-ng/statping-ng/types/hits"
+	"github.com/statping-ng/statping-ng/types/metrics"
+	"github.com/statping-ng/statping-ng/utils"
+	"github.com/tidwall/gjson"
+)
+
+// HttpStep is a single request of a 'http_steps' service. The URL, Headers, Body and assertion values can use
+// variables extracted from earlier steps with '{{name}}'. URLs starting with '/' are relative to the service domain.
+type HttpStep struct {
+	Name       string       `json:"name"`
+	Method     string       `json:"method"`
+	URL        string       `json:"url"`
+	Headers    string       `json:"headers,omitempty"`
+	Body       string       `json:"body,omitempty"`
+	Assertions []Assertion  `json:"assertions,omitempty"`
+	Extract    []Extraction `json:"extract,omitempty"`
+}
+
+// Extraction stores a value from the response of a step as a variable for the following steps.
+// The Source is 'json' (gjson path), 'header' (header name), 'regex' (first capture group) or 'cookie' (cookie name).
+type Extraction struct {
+	Name     string `json:"name"`
+	Source   string `json:"source"`
+	Property string `json:"property"`
+}
+
+// StepResult contains the result of a single step from the last check of a 'http_steps' service
+type StepResult struct {
+	Name       string `json:"name"`
+	StatusCode int    `json:"status_code"`
+	Latency    int64  `json:"latency"`
+	Error      string `json:"error,omitempty"`
+}
+
+var stepVariable = regexp.MustCompile(`{{\s*([\w.-]+)\s*}}`)
+
+// ParseSteps returns the steps stored as a JSON array on the service
+func (s *Service) ParseSteps() ([]HttpStep, error) {
+	var steps []HttpStep
+	if strings.TrimSpace(s.Steps.String) == "" {
+		return nil, errors.New("service has no steps")
+	}
+	if err := json.Unmarshal([]byte(s.Steps.String), &steps); err != nil {
+		return nil, errors.Wrap(err, "invalid steps")
+	}
+	if len(steps) == 0 {
+		return nil, errors.New("service has no steps")
+	}
+	for i := range steps {
+		if steps[i].Name == "" {
+			steps[i].Name = fmt.Sprintf("Step %d", i+1)
+		}
+		if steps[i].URL == "" {
+			return nil, fmt.Errorf("%s has no URL", steps[i].Name)
+		}
+		for _, e := range steps[i].Extract {
+			switch e.Source {
+			case "json", "header", "regex", "cookie":
+			default:
+				return nil, fmt.Errorf("%s extracts '%s' from an unknown source '%s'", steps[i].Name, e.Name, e.Source)
+			}
+		}
+	}
+	return steps, nil
+}
+
+// substituteVars replaces '{{name}}' with the value of the variable, it returns an error for unknown variables
+func substituteVars(text string, vars map[string]string) (string, error) {
+	var missing []string
+	out := stepVariable.ReplaceAllStringFunc(text, func(match string) string {
+		name := stepVariable.FindStringSubmatch(match)[1]
+		val, ok := vars[name]
+		if !ok {
+			missing = append(missing, name)
+			return match
+		}
+		return val
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("unknown variable '%s'", strings.Join(missing, "', '"))
+	}
+	return out, nil
+}
+
+// stepURL returns the absolute URL for the step, relative URLs are resolved against the service domain
+func (s *Service) stepURL(endpoint string) (string, error) {
+	if !strings.HasPrefix(endpoint, "/") {
+		return endpoint, nil
+	}
+	base, err := url.Parse(s.Domain)
+	if err != nil {
+		return "", err
+	}
+	rel, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(rel).String(), nil
+}
+
+// extract returns the value of the extraction from the response
+func (e Extraction) extract(res *http.Response, body []byte) (string, error) {
+	switch e.Source {
+	case "json":
+		result := gjson.GetBytes(body, e.Property)
+		if result.Exists() {
+			return result.String(), nil
+		}
+	case "header":
+		if values, ok := res.Header[http.CanonicalHeaderKey(e.Property)]; ok {
+			return strings.Join(values, ","), nil
+		}
+	case "regex":
+		re, err := regexp.Compile(e.Property)
+		if err != nil {
+			return "", err
+		}
+		match := re.FindSubmatch(body)
+		if len(match) > 1 {
+			return string(match[1]), nil
+		} else if len(match) == 1 {
+			return string(match[0]), nil
+		}
+	case "cookie":
+		for _, c := range res.Cookies() {
+			if c.Name == e.Property {
+				return c.Value, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("could not extract '%s' from %s '%s'", e.Name, e.Source, e.Property)
+}
+
+// runStep sends the request for a single step, checks its assertions and adds the extracted values to vars
+func (s *Service) runStep(step HttpStep, vars map[string]string, result *StepResult) error {
+	endpoint, err := substituteVars(step.URL, vars)
+	if err != nil {
+		return err
+	}
+	if endpoint, err = s.stepURL(endpoint); err != nil {
+		return err
+	}
+	headerList, err := substituteVars(step.Headers, vars)
+	if err != nil {
+		return err
+	}
+	body, err := substituteVars(step.Body, vars)
+	if err != nil {
+		return err
+	}
+
+	assertions := make([]Assertion, len(step.Assertions))
+	for i, a := range step.Assertions {
+		if a.Value, err = substituteVars(a.Value, vars); err != nil {
+			return err
+		}
+		assertions[i] = a
+	}
+
+	var headers []string
+	if headerList != "" {
+		headers = strings.Split(headerList, ",")
+	}
+	if s.Redirect.Bool {
+		headers = append(headers, "Redirect=true")
+	}
+	if proxy := s.proxyURL(); proxy != "" {
+		headers = append(headers, "Proxy="+proxy)
+	}
+	var contentType interface{}
+	if body != "" {
+		contentType = "application/json"
+	}
+
+	customTLS, err := s.LoadTLSCert()
+	if err != nil {
+		log.Errorln(err)
+	}
+
+	t1 := utils.Now()
+	content, res, err := utils.HttpRequest(endpoint, step.Method, contentType, headers, bytes.NewBufferString(body), time.Duration(s.Timeout)*time.Second, s.VerifySSL.Bool, customTLS)
+	result.Latency = utils.Now().Sub(t1).Microseconds()
+	if err != nil {
+		return errors.Wrap(err, "HTTP Error")
+	}
+	result.StatusCode = res.StatusCode
+	s.LastResponse = string(content)
+
+	// without a status code assertion every 2xx and 3xx response is successful
+	if !hasStatusAssertion(assertions) && res.StatusCode >= 400 {
+		return fmt.Errorf("HTTP Status Code %v", res.StatusCode)
+	}
+	failed, soft := checkAssertions(assertions, &assertionResponse{
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Body:       content,
+		Latency:    time.Duration(result.Latency) * time.Microsecond,
+	})
+	if len(failed) > 0 {
+		return fmt.Errorf("assertions failed: %s", strings.Join(failed, "; "))
+	}
+	for _, f := range soft {
+		s.softFailures = append(s.softFailures, fmt.Sprintf("%s: %s", step.Name, f))
+	}
+
+	for _, e := range step.Extract {
+		val, err := e.extract(res, content)
+		if err != nil {
+			return err
+		}
+		vars[e.Name] = val
+	}
+	return nil
+}
+
+// CheckHttpSteps will run each step of a 'http_steps' service in order, the service is offline if any step fails
+func CheckHttpSteps(s *Service, record bool) (*Service, error) {
+	defer s.updateLastCheck()
+	timer := prometheus.NewTimer(metrics.ServiceTimer(s.Name))
+	defer timer.ObserveDuration()
+
+	steps, err := s.ParseSteps()
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("HTTP Steps Error %v", err), "steps")
+		}
+		return s, err
+	}
+
+	dnsLookup, err := dnsCheck(s)
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("Could not get IP address for domain %v, %v", s.Domain, err), "lookup")
+		}
+		return s, err
+	}
+	s.PingTime = dnsLookup
+
+	vars := make(map[string]string)
+	s.StepResults = make([]*StepResult, 0, len(steps))
+	s.softFailures = nil
+	s.Latency = 0
+	for _, step := range steps {
+		result := &StepResult{Name: step.Name}
+		s.StepResults = append(s.StepResults, result)
+		err := s.runStep(step, vars, result)
+		s.Latency += result.Latency
+		s.LastStatusCode = result.StatusCode
+		if err != nil {
+			result.Error = err.Error()
+			if record {
+				RecordFailure(s, fmt.Sprintf("HTTP Step '%s' failed: %v", step.Name, err), "step")
+			}
+			return s, err
+		}
+	}
+
+	s.Online = true
+	if record {
+		RecordSuccess(s)
+	}
+	return s, nil
+}
+
+// stepLatencies returns the latency of each step from the last check, stored with the service hit
+func (s *Service) stepLatencies() hits.StepLatencies {
+	if len(s.StepResults) == 0 {
+		return nil
+	}
+	steps := make(hits.StepLatencies, len(s.StepResults))
+	for i, r := range s.StepResults {
+		steps[i] = hits.StepLatency{Name: r.Name, Latency: r.Latency}
+	}
+	return steps
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/statping-ng/statping-ng/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stepsServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		var login struct {
+			Username string `json:"username"`
+		}
+		json.NewDecoder(r.Body).Decode(&login)
+		if r.Method != "POST" || login.Username != "admin" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3ss10n"})
+		w.Header().Set("X-Request-Id", "req-42")
+		w.Write([]byte(`{"data":{"token":"abc123","user_id":7}}`))
+	})
+	mux.HandleFunc("/api/users/7", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer abc123" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`<html><title>admin profile</title></html>`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestSubstituteVars(t *testing.T) {
+	vars := map[string]string{"token": "abc", "user.id": "7"}
+
+	out, err := substituteVars("/users/{{user.id}}?token={{ token }}", vars)
+	require.Nil(t, err)
+	assert.Equal(t, "/users/7?token=abc", out)
+
+	_, err = substituteVars("Authorization=Bearer {{missing}}", vars)
+	assert.EqualError(t, err, "unknown variable 'missing'")
+}
+
+func TestCheckHttpSteps(t *testing.T) {
+	utils.InitEnvs()
+	server := stepsServer()
+	defer server.Close()
+
+	steps := []HttpStep{
+		{
+			Name:   "Login",
+			Method: "POST",
+			URL:    "/login",
+			Body:   `{"username":"admin"}`,
+			Extract: []Extraction{
+				{Name: "token", Source: "json", Property: "data.token"},
+				{Name: "user", Source: "json", Property: "data.user_id"},
+				{Name: "session", Source: "cookie", Property: "session"},
+				{Name: "request", Source: "header", Property: "X-Request-Id"},
+			},
+		},
+		{
+			Name:    "Profile",
+			Method:  "GET",
+			URL:     server.URL + "/api/users/{{user}}",
+			Headers: "Authorization=Bearer {{token}},Cookie=session={{session}},X-Request-Id={{request}}",
+			Assertions: []Assertion{
+				{Source: "status_code", Comparison: "equals", Value: "200"},
+			},
+			Extract: []Extraction{
+				{Name: "title", Source: "regex", Property: `<title>(\w+) profile</title>`},
+			},
+		},
+		{
+			Name:    "Profile Title",
+			Method:  "GET",
+			URL:     "/api/users/{{user}}",
+			Headers: "Authorization=Bearer {{token}}",
+			Assertions: []Assertion{
+				{Source: "body", Comparison: "contains", Value: "{{title}}"},
+			},
+		},
+	}
+	data, err := json.Marshal(steps)
+	require.Nil(t, err)
+
+	s := &Service{
+		Name:    "HTTP Steps",
+		Domain:  server.URL,
+		Type:    "http_steps",
+		Timeout: 2,
+		Steps:   null.NewNullString(string(data)),
+	}
+	s.CheckService(false)
+	assert.True(t, s.Online)
+	require.Len(t, s.StepResults, 3)
+	assert.Equal(t, "Login", s.StepResults[0].Name)
+	assert.Equal(t, 200, s.StepResults[1].StatusCode)
+	assert.Empty(t, s.StepResults[2].Error)
+	assert.Len(t, s.stepLatencies(), 3)
+
+	var total int64
+	for _, r := range s.StepResults {
+		total += r.Latency
+	}
+	assert.Equal(t, total, s.Latency)
+
+	steps[0].Body = `{"username":"guest"}`
+	data, _ = json.Marshal(steps)
+	s.Steps = null.NewNullString(string(data))
+	s.Online = false
+	_, err = CheckHttpSteps(s, false)
+	assert.False(t, s.Online)
+	assert.EqualError(t, err, "HTTP Status Code 401")
+	require.Len(t, s.StepResults, 1)
+	assert.Equal(t, "HTTP Status Code 401", s.StepResults[0].Error)
+
+	steps[0].Body = `{"username":"admin"}`
+	steps[0].Extract = steps[0].Extract[:1]
+	data, _ = json.Marshal(steps)
+	s.Steps = null.NewNullString(string(data))
+	_, err = CheckHttpSteps(s, false)
+	assert.EqualError(t, err, "unknown variable 'user'")
+	require.Len(t, s.StepResults, 2)
+	assert.Equal(t, "Profile", s.StepResults[1].Name)
+}
+
+func TestParseSteps(t *testing.T) {
+	s := &Service{}
+	_, err := s.ParseSteps()
+	assert.NotNil(t, err)
+
+	s.Steps = null.NewNullString(`[{"url":"/health"},{"name":"Second","url":"/api"}]`)
+	steps, err := s.ParseSteps()
+	require.Nil(t, err)
+	assert.Equal(t, "Step 1", steps[0].Name)
+	assert.Equal(t, "Second", steps[1].Name)
+
+	s.Steps = null.NewNullString(`[{"name":"No URL"}]`)
+	_, err = s.ParseSteps()
+	assert.EqualError(t, err, "No URL has no URL")
+
+	s.Steps = null.NewNullString(`[{"url":"/","extract":[{"name":"x","source":"xml","property":"a"}]}]`)
+	_, err = s.ParseSteps()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tcpBannerServer starts a local TCP server that sends a banner and answers 'PING' lines with '+PONG',
+// the line 'QUIT' closes the connection without a reply
+func tcpBannerServer(t *testing.T) (*net.TCPAddr, func()) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				conn.Write([]byte("SSH-2.0-OpenSSH_8.9\r\n"))
+				r := bufio.NewReader(conn)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					switch strings.TrimSpace(line) {
+					case "PING":
+						conn.Write([]byte("+PONG\r\n"))
+					case "QUIT":
+						return
+					}
+				}
+			}(conn)
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr), func() { ln.Close() }
+}
+
+func TestCheckTcpSendExpect(t *testing.T) {
+	addr, stop := tcpBannerServer(t)
+	defer stop()
+
+	tests := []struct {
+		Name     string
+		Payload  string
+		Encoding string
+		Expected string
+		Prefix   string
+		Online   bool
+		Response string
+	}{
+		{"Connect Only", "", "", "", "", true, ""},
+		{"Banner Regex", "", "", "^SSH-2\\.0-", "", true, "SSH-2.0-OpenSSH_8.9\r\n"},
+		{"Banner Prefix", "", "", "", "SSH-2.0", true, "SSH-2.0-OpenSSH_8.9\r\n"},
+		{"Banner Mismatch", "", "", "^220 ", "", false, "SSH-2.0-OpenSSH_8.9\r\n"},
+		{"Send And Expect", "PING\r\n", "", "\\+PONG", "", true, "SSH-2.0-OpenSSH_8.9\r\n+PONG\r\n"},
+		{"Send Hex And Expect", "50 49 4e 47 0d 0a", "hex", "\\+PONG", "5353482d", true, "5353482d322e302d4f70656e5353485f382e390d0a2b504f4e470d0a"},
+		{"Closed Without Match", "QUIT\r\n", "", "\\+PONG", "", false, "SSH-2.0-OpenSSH_8.9\r\n"},
+		{"Invalid Hex Payload", "zz", "hex", "", "", false, ""},
+	}
+
+	for _, v := range tests {
+		t.Run(v.Name, func(t *testing.T) {
+			s := &Service{
+				Name:            v.Name,
+				Domain:          "127.0.0.1",
+				Port:            addr.Port,
+				Type:            "tcp",
+				Timeout:         1,
+				Payload:         null.NewNullString(v.Payload),
+				PayloadEncoding: v.Encoding,
+				Expected:        null.NewNullString(v.Expected),
+				ExpectedPrefix:  null.NewNullString(v.Prefix),
+			}
+			s.CheckService(false)
+			assert.Equal(t, v.Online, s.Online)
+			assert.Equal(t, v.Response, s.LastResponse)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/statping-ng/statping-ng/types/metrics"
+	"github.com/statping-ng/statping-ng/utils"
+)
+
+// udpUnreachableWait is how long a UDP service without a payload or expected response
+// waits for an ICMP port unreachable before it is considered online
+const udpUnreachableWait = 2 * time.Second
+
+// isPortUnreachable returns true if the error was caused by an ICMP port unreachable message
+func isPortUnreachable(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || strings.Contains(err.Error(), "connection refused")
+}
+
+// CheckUdp will send the payload of the service in a UDP datagram and wait for a reply
+func CheckUdp(s *Service, record bool) (*Service, error) {
+	defer s.updateLastCheck()
+	timer := prometheus.NewTimer(metrics.ServiceTimer(s.Name))
+	defer timer.ObserveDuration()
+
+	dnsLookup, err := dnsCheck(s)
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("Could not get IP address for UDP service %v, %v", s.Domain, err), "lookup")
+		}
+		return s, err
+	}
+	s.PingTime = dnsLookup
+	domain := fmt.Sprintf("%v", s.Domain)
+	if s.Port != 0 {
+		domain = fmt.Sprintf("%v:%v", s.Domain, s.Port)
+		if isIPv6(s.Domain) {
+			domain = fmt.Sprintf("[%v]:%v", s.Domain, s.Port)
+		}
+	}
+
+	payload, err := s.decodePayload(s.Payload.String)
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("UDP Payload Error: %v", err), "payload")
+		}
+		return s, err
+	}
+
+	timeout := time.Duration(s.Timeout) * time.Second
+	conn, err := net.DialTimeout("udp", domain, timeout)
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("Dial Error: %v", err), "connection")
+		}
+		return s, err
+	}
+	defer conn.Close()
+
+	// without a payload or expected response there is nothing to wait for, only
+	// an ICMP port unreachable can prove the service is offline
+	waitReply := len(payload) > 0 || s.expectsResponse()
+	wait := timeout
+	if !waitReply && udpUnreachableWait < wait {
+		wait = udpUnreachableWait
+	}
+
+	t1 := utils.Now()
+	conn.SetDeadline(t1.Add(wait))
+	if _, err := conn.Write(payload); err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("UDP Write Error: %v", err), "write")
+		}
+		return s, err
+	}
+
+	buf := make([]byte, 65535)
+	n, err := conn.Read(buf)
+	s.Latency = utils.Now().Sub(t1).Microseconds()
+	if err != nil {
+		if isPortUnreachable(err) {
+			if record {
+				RecordFailure(s, fmt.Sprintf("UDP port %v is unreachable: %v", domain, err), "unreachable")
+			}
+			return s, err
+		}
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() && !waitReply {
+			s.LastResponse = ""
+			s.Online = true
+			if record {
+				RecordSuccess(s)
+			}
+			return s, nil
+		}
+		if record {
+			RecordFailure(s, fmt.Sprintf("UDP did not receive a reply within %v: %v", wait, err), "timeout")
+		}
+		return s, err
+	}
+
+	reply := buf[:n]
+	s.LastResponse = s.encodeResponse(reply)
+	if err := s.matchResponse(reply); err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("UDP %v", err), "response_body")
+		}
+		return s, err
+	}
+
+	s.Online = true
+	if record {
+		RecordSuccess(s)
+	}
+	return s, nil
+}
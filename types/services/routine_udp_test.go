@@ -0,0 +1,84 @@
+package services
+
+import (
+	"net"
+	"testing"
+
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// udpServer starts a local UDP server that replies with 'pong ' and the received payload,
+// a datagram containing only 'silent' will not be answered
+func udpServer(t *testing.T) (*net.UDPAddr, func()) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if string(buf[:n]) == "silent" {
+				continue
+			}
+			pc.WriteTo(append([]byte("pong "), buf[:n]...), addr)
+		}
+	}()
+	return pc.LocalAddr().(*net.UDPAddr), func() { pc.Close() }
+}
+
+// closedUdpPort returns a local UDP port that nothing is listening on
+func closedUdpPort(t *testing.T) int {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+	port := pc.LocalAddr().(*net.UDPAddr).Port
+	pc.Close()
+	return port
+}
+
+func TestCheckUdp(t *testing.T) {
+	addr, stop := udpServer(t)
+	defer stop()
+
+	tests := []struct {
+		Name     string
+		Port     int
+		Payload  string
+		Encoding string
+		Expected string
+		Prefix   string
+		Online   bool
+		Response string
+	}{
+		{"Text Payload With Regex", addr.Port, "ping", "", "^pong ping$", "", true, "pong ping"},
+		{"Text Payload Mismatch", addr.Port, "ping", "", "^pang", "", false, "pong ping"},
+		{"Hex Payload With Prefix", addr.Port, "de ad be ef", "hex", "", "706f6e67", true, "706f6e6720deadbeef"},
+		{"Hex Prefix Mismatch", addr.Port, "deadbeef", "hex", "", "ffff", false, "706f6e6720deadbeef"},
+		{"Invalid Hex Payload", addr.Port, "zz", "hex", "", "", false, ""},
+		{"No Reply", addr.Port, "silent", "", "", "", false, ""},
+		{"Port Unreachable", closedUdpPort(t), "ping", "", "", "", false, ""},
+		{"Port Unreachable Without Payload", closedUdpPort(t), "", "", "", "", false, ""},
+	}
+
+	for _, v := range tests {
+		t.Run(v.Name, func(t *testing.T) {
+			s := &Service{
+				Name:            v.Name,
+				Domain:          "127.0.0.1",
+				Port:            v.Port,
+				Type:            "udp",
+				Timeout:         1,
+				Payload:         null.NewNullString(v.Payload),
+				PayloadEncoding: v.Encoding,
+				Expected:        null.NewNullString(v.Expected),
+				ExpectedPrefix:  null.NewNullString(v.Prefix),
+			}
+			s.CheckService(false)
+			assert.Equal(t, v.Online, s.Online)
+			assert.Equal(t, v.Response, s.LastResponse)
+		})
+	}
+}
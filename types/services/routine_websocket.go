@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/statping-ng/statping-ng/types/errors"
+	"github.com/statping-ng/statping-ng/types/hits"
+	"github.com/statping-ng/statping-ng/types/metrics"
+	"github.com/statping-ng/statping-ng/utils"
+)
+
+// CheckWebsocket will upgrade a ws:// or wss:// URL to a WebSocket with the headers of the service. The payload
+// is sent as a text message, or as a binary message for hex payloads, and the check waits for a reply that
+// matches the expected prefix, regex and assertions. The connect and reply latency are recorded as steps.
+func CheckWebsocket(s *Service, record bool) (*Service, error) {
+	defer s.updateLastCheck()
+	timer := prometheus.NewTimer(metrics.ServiceTimer(s.Name))
+	defer timer.ObserveDuration()
+	s.wsLatencies = nil
+
+	dnsLookup, err := dnsCheck(s)
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("Could not get IP address for WebSocket service %v, %v", s.Domain, err), "lookup")
+		}
+		return s, err
+	}
+	s.PingTime = dnsLookup
+
+	assertions, err := s.ParseAssertions()
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("WebSocket Assertions Error %v", err), "assertion")
+		}
+		return s, err
+	}
+	payload, err := s.decodePayload(s.Payload.String)
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("WebSocket Payload Error: %v", err), "payload")
+		}
+		return s, err
+	}
+	dialer, err := s.websocketDialer()
+	if err != nil {
+		if record {
+			RecordFailure(s, fmt.Sprintf("WebSocket Proxy Error: %v", err), "proxy")
+		}
+		return s, err
+	}
+
+	timeout := time.Duration(s.Timeout) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	s.softFailures = nil
+	s.LastResponse = ""
+	t1 := utils.Now()
+	conn, res, err := dialer.DialContext(ctx, s.Domain, s.websocketHeader())
+	if err != nil {
+		recordFailedCertificate(s, err, parseHost(s))
+		if res != nil {
+			s.LastStatusCode = res.StatusCode
+			err = fmt.Errorf("handshake failed with status %d, %v", res.StatusCode, err)
+		}
+		if record {
+			RecordFailure(s, fmt.Sprintf("WebSocket Error %v", err), "handshake")
+		}
+		return s, err
+	}
+	defer conn.Close()
+	connected := utils.Now().Sub(t1)
+	s.LastStatusCode = res.StatusCode
+	if tlsConn, ok := conn.UnderlyingConn().(*tls.Conn); ok {
+		state := tlsConn.ConnectionState()
+		recordCertificate(s, &state, parseHost(s), record)
+	}
+	latencies := hits.StepLatencies{{Name: "Connect", Latency: connected.Microseconds()}}
+
+	deadline := t1.Add(timeout)
+	var reply []byte
+	var replied time.Duration
+	if len(payload) > 0 || s.expectsResponse() || needsReply(assertions) {
+		t2 := utils.Now()
+		reply, err = s.websocketExchange(conn, payload, deadline)
+		replied = utils.Now().Sub(t2)
+		s.LastResponse = s.encodeResponse(reply)
+		if err != nil {
+			if record {
+				RecordFailure(s, fmt.Sprintf("WebSocket Reply Error: %v", err), "reply")
+			}
+			return s, err
+		}
+		if reply != nil {
+			latencies = append(latencies, hits.StepLatency{Name: "Reply", Latency: replied.Microseconds()})
+		}
+	}
+	s.Latency = (connected + replied).Microseconds()
+
+	if len(assertions) > 0 {
+		failed, soft := checkAssertions(assertions, &assertionResponse{
+			StatusCode: res.StatusCode,
+			Header:     res.Header,
+			Body:       reply,
+			Latency:    time.Duration(s.Latency) * time.Microsecond,
+		})
+		if len(failed) > 0 {
+			err = fmt.Errorf("%d of %d assertions failed", len(failed), len(assertions))
+			if record {
+				RecordFailure(s, fmt.Sprintf("WebSocket Assertions failed: %s", strings.Join(failed, "; ")), "assertion")
+			}
+			return s, err
+		}
+		s.softFailures = soft
+	}
+
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), deadline)
+
+	s.wsLatencies = latencies
+	s.Online = true
+	if record {
+		RecordSuccess(s)
+	}
+	return s, nil
+}
+
+// websocketDialer returns the dialer for the handshake with the TLS settings, proxy and connect address of the service
+func (s *Service) websocketDialer() (*websocket.Dialer, error) {
+	dial, err := s.dialContext()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: !s.VerifySSL.Bool,
+	}
+	customTLS, err := s.LoadTLSCert()
+	if err != nil {
+		log.Errorln(err)
+	}
+	if customTLS != nil {
+		tlsConfig.RootCAs = customTLS.RootCAs
+		tlsConfig.Certificates = customTLS.Certificates
+	}
+	return &websocket.Dialer{
+		HandshakeTimeout: time.Duration(s.Timeout) * time.Second,
+		TLSClientConfig:  tlsConfig,
+		NetDialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			// the Host header and TLS server name stay the same when dialing another address
+			if address := s.connectAddress(); address != "" {
+				if _, port, err := net.SplitHostPort(addr); err == nil {
+					addr = net.JoinHostPort(address, port)
+				}
+			}
+			return dial(ctx, network, addr)
+		},
+	}, nil
+}
+
+// websocketHeader returns the headers of the service for the handshake request
+func (s *Service) websocketHeader() http.Header {
+	header := make(http.Header)
+	if !s.Headers.Valid {
+		return header
+	}
+	for _, h := range strings.Split(s.Headers.String, ",") {
+		keyVal := strings.SplitN(h, "=", 2)
+		if len(keyVal) == 2 && strings.TrimSpace(keyVal[0]) != "" {
+			header.Add(strings.TrimSpace(keyVal[0]), strings.TrimSpace(keyVal[1]))
+		}
+	}
+	return header
+}
+
+// websocketExchange sends the payload and reads messages until one matches the expected byte prefix and regex
+// of the service, or the deadline expires. The last message received is always returned.
+func (s *Service) websocketExchange(conn *websocket.Conn, payload []byte, deadline time.Time) ([]byte, error) {
+	conn.SetWriteDeadline(deadline)
+	conn.SetReadDeadline(deadline)
+	conn.SetReadLimit(maxExpectSize)
+	if len(payload) > 0 {
+		messageType := websocket.TextMessage
+		if s.PayloadEncoding == "hex" {
+			messageType = websocket.BinaryMessage
+		}
+		if err := conn.WriteMessage(messageType, payload); err != nil {
+			return nil, errors.Wrap(err, "write error")
+		}
+	}
+
+	var last []byte
+	var matchErr error
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if last == nil {
+				return nil, errors.Wrap(err, "no reply received")
+			}
+			return last, matchErr
+		}
+		last = data
+		if matchErr = s.matchResponse(data); matchErr == nil {
+			return data, nil
+		}
+	}
+}
+
+// needsReply returns true if any of the assertions checks the reply message
+func needsReply(assertions []Assertion) bool {
+	for _, a := range assertions {
+		if a.Source == "json" || a.Source == "body" || a.Source == "body_size" {
+			return true
+		}
+	}
+	return false
+}
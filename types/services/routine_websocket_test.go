@@ -0,0 +1,134 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// websocketServer starts a WebSocket server that requires the 'X-Api-Key' header. It greets with a welcome
+// message, answers 'ping' with 'pong', 'status' with a JSON status, echoes binary messages and ignores 'wait'.
+func websocketServer(t *testing.T) string {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secret" {
+			http.Error(w, "missing api key", http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, http.Header{"X-Server": []string{"statping"}})
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.WriteMessage(websocket.TextMessage, []byte("welcome"))
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			switch {
+			case messageType == websocket.BinaryMessage:
+				conn.WriteMessage(websocket.BinaryMessage, data)
+			case string(data) == "ping":
+				conn.WriteMessage(websocket.TextMessage, []byte("pong"))
+			case string(data) == "status":
+				conn.WriteMessage(websocket.TextMessage, []byte(`{"status":"ok","clients":3}`))
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestCheckWebsocket(t *testing.T) {
+	endpoint := websocketServer(t)
+
+	tests := []struct {
+		Name       string
+		Headers    string
+		Encoding   string
+		Payload    string
+		Prefix     string
+		Expected   string
+		Assertions string
+		Online     bool
+		Response   string
+		Steps      int
+	}{
+		{"Handshake", "X-Api-Key=secret", "text", "", "", "", "", true, "", 1},
+		{"Handshake Assertion", "X-Api-Key=secret", "text", "", "", "", `[{"source":"header","property":"X-Server","comparison":"equals","value":"statping"}]`, true, "", 1},
+		{"Greeting", "X-Api-Key=secret", "text", "", "", "^welcome$", "", true, "welcome", 2},
+		{"Text Reply", "X-Api-Key=secret", "text", "ping", "", "^pong$", "", true, "pong", 2},
+		{"Reply Prefix", "X-Api-Key=secret", "text", "ping", "po", "", "", true, "pong", 2},
+		{"JSON Assertion", "X-Api-Key=secret", "text", "status", "", "clients", `[{"source":"json","property":"clients","comparison":"greater_than","value":"2"}]`, true, `{"status":"ok","clients":3}`, 2},
+		{"Failed JSON Assertion", "X-Api-Key=secret", "text", "status", "", "status", `[{"source":"json","property":"status","comparison":"equals","value":"down"}]`, false, `{"status":"ok","clients":3}`, 0},
+		{"Binary Reply", "X-Api-Key=secret", "hex", "de ad be ef", "dead", "", "", true, "deadbeef", 2},
+		{"No Matching Reply", "X-Api-Key=secret", "text", "wait", "", "^pong$", "", false, "welcome", 0},
+	}
+
+	for _, v := range tests {
+		t.Run(v.Name, func(t *testing.T) {
+			s := &Service{
+				Name:            v.Name,
+				Domain:          endpoint,
+				Type:            "websocket",
+				Timeout:         1,
+				Headers:         null.NewNullString(v.Headers),
+				PayloadEncoding: v.Encoding,
+				Payload:         null.NewNullString(v.Payload),
+				ExpectedPrefix:  null.NewNullString(v.Prefix),
+				Expected:        null.NewNullString(v.Expected),
+				Assertions:      null.NewNullString(v.Assertions),
+			}
+			s.CheckService(false)
+			assert.Equal(t, v.Online, s.Online)
+			assert.Equal(t, v.Response, s.LastResponse)
+			require.Len(t, s.wsLatencies, v.Steps)
+			if v.Online {
+				assert.Equal(t, http.StatusSwitchingProtocols, s.LastStatusCode)
+				assert.Equal(t, "Connect", s.wsLatencies[0].Name)
+				var total int64
+				for _, l := range s.wsLatencies {
+					total += l.Latency
+				}
+				assert.InDelta(t, total, s.Latency, float64(len(s.wsLatencies)))
+			}
+		})
+	}
+
+	t.Run("Handshake Status", func(t *testing.T) {
+		s := &Service{
+			Name:    "Handshake Status",
+			Domain:  endpoint,
+			Type:    "websocket",
+			Timeout: 1,
+		}
+		s.CheckService(false)
+		assert.False(t, s.Online)
+		assert.Equal(t, http.StatusUnauthorized, s.LastStatusCode)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		s := &Service{
+			Name:     "Timeout",
+			Domain:   endpoint,
+			Type:     "websocket",
+			Timeout:  1,
+			Headers:  null.NewNullString("X-Api-Key=secret"),
+			Payload:  null.NewNullString("wait"),
+			Expected: null.NewNullString("never"),
+		}
+		t1 := time.Now()
+		s.CheckService(false)
+		assert.False(t, s.Online)
+		assert.Less(t, time.Since(t1), 3*time.Second)
+	})
+}
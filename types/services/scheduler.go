@@ -0,0 +1,346 @@
+package services
+
+import (
+	"container/heap"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/statping-ng/statping-ng/types/metrics"
+	"github.com/statping-ng/statping-ng/utils"
+)
+
+const (
+	defaultSchedulerWorkers   = 100
+	defaultSchedulerHostLimit = 10
+	defaultSchedulerJitter    = 2 * time.Second
+	// minInterval keeps a service without an interval from being checked in a loop
+	minInterval = time.Second
+)
+
+// checkScheduler runs the checks of every service started with ServiceCheckQueue
+var checkScheduler = newScheduler(0, 0, 0)
+
+// scheduledCheck is a service in the queue of the scheduler
+type scheduledCheck struct {
+	service    *Service
+	record     bool
+	running    chan bool
+	host       string
+	next       time.Time
+	checkpoint time.Time
+	attempts   checkAttempts
+	removed    bool
+	index      int
+}
+
+// stopped returns true if the service was closed after the check was scheduled
+func (c *scheduledCheck) stopped() bool {
+	if c.removed || c.running == nil {
+		return true
+	}
+	select {
+	case <-c.running:
+		return true
+	default:
+		return false
+	}
+}
+
+// reschedule sets the next check after a check finished at 'now'. Online services keep their checkpoint
+// so the interval does not drift, offline services are checked again one interval after the failure.
+// Intervals that already passed are skipped instead of being checked in a burst, the amount is returned.
+func (c *scheduledCheck) reschedule(now time.Time, online bool, interval, jitter time.Duration) int {
+	if interval < minInterval {
+		interval = minInterval
+	}
+	if !online || c.checkpoint.IsZero() {
+		c.checkpoint = now
+	}
+	c.checkpoint = c.checkpoint.Add(interval)
+
+	var skipped int
+	if behind := now.Sub(c.checkpoint); behind >= 0 {
+		skipped = int(behind/interval) + 1
+		c.checkpoint = c.checkpoint.Add(time.Duration(skipped) * interval)
+	}
+	c.next = c.checkpoint.Add(randomJitter(jitter, interval))
+	return skipped
+}
+
+// randomJitter returns a random delay up to the jitter and at most a tenth of the interval
+func randomJitter(jitter, interval time.Duration) time.Duration {
+	if max := interval / 10; jitter > max {
+		jitter = max
+	}
+	if jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(jitter)))
+}
+
+// checkQueue is a heap of scheduled checks ordered by the time of their next check
+type checkQueue []*scheduledCheck
+
+func (q checkQueue) Len() int           { return len(q) }
+func (q checkQueue) Less(i, j int) bool { return q[i].next.Before(q[j].next) }
+func (q checkQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *checkQueue) Push(x interface{}) {
+	c := x.(*scheduledCheck)
+	c.index = len(*q)
+	*q = append(*q, c)
+}
+
+func (q *checkQueue) Pop() interface{} {
+	old := *q
+	c := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	c.index = -1
+	return c
+}
+
+// scheduler runs the due checks of its queue on a pool of workers, a host never has more than
+// 'hostLimit' checks running at the same time and a service never has more than one
+type scheduler struct {
+	mu        sync.Mutex
+	once      sync.Once
+	queue     checkQueue
+	checks    map[*Service]*scheduledCheck
+	hosts     map[string]int
+	waiting   map[string][]*scheduledCheck
+	inFlight  map[*Service]bool
+	pending   map[*Service]*scheduledCheck
+	running   int
+	workers   int
+	hostLimit int
+	jitter    time.Duration
+	wake      chan struct{}
+	jobs      chan *scheduledCheck
+	run       func(s *Service, attempts *checkAttempts, record bool) bool
+}
+
+// newScheduler returns a scheduler, a zero value uses the SCHEDULER_WORKERS, SCHEDULER_HOST_LIMIT
+// and SCHEDULER_JITTER settings when the scheduler starts. A negative host limit does not limit the hosts.
+func newScheduler(workers, hostLimit int, jitter time.Duration) *scheduler {
+	return &scheduler{
+		checks:    make(map[*Service]*scheduledCheck),
+		hosts:     make(map[string]int),
+		waiting:   make(map[string][]*scheduledCheck),
+		inFlight:  make(map[*Service]bool),
+		pending:   make(map[*Service]*scheduledCheck),
+		workers:   workers,
+		hostLimit: hostLimit,
+		jitter:    jitter,
+		wake:      make(chan struct{}, 1),
+		jobs:      make(chan *scheduledCheck),
+		run: func(s *Service, attempts *checkAttempts, record bool) bool {
+			if s.checkAttempt(attempts, record) {
+				return true
+			}
+			s.updateDegraded()
+			s.UpdateStats()
+			return false
+		},
+	}
+}
+
+// start reads the settings of the scheduler and starts the dispatcher and the workers
+func (sc *scheduler) start() {
+	if utils.Params != nil {
+		if sc.workers == 0 {
+			sc.workers = utils.Params.GetInt("SCHEDULER_WORKERS")
+		}
+		if sc.hostLimit == 0 {
+			sc.hostLimit = utils.Params.GetInt("SCHEDULER_HOST_LIMIT")
+		}
+		if sc.jitter == 0 {
+			sc.jitter = utils.Params.GetDuration("SCHEDULER_JITTER")
+		}
+	}
+	if sc.workers <= 0 {
+		sc.workers = defaultSchedulerWorkers
+	}
+	if sc.hostLimit == 0 {
+		sc.hostLimit = defaultSchedulerHostLimit
+	}
+	if sc.jitter == 0 {
+		sc.jitter = defaultSchedulerJitter
+	}
+	log.Infof("Starting check scheduler with %d workers and at most %d checks per host", sc.workers, sc.hostLimit)
+
+	go sc.dispatch()
+	for i := 0; i < sc.workers; i++ {
+		go sc.work()
+	}
+}
+
+// add queues the service, its first check runs within the jitter. A service that is already
+// queued with the same running channel is not added twice.
+func (sc *scheduler) add(s *Service, record bool) {
+	sc.once.Do(sc.start)
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if c, ok := sc.checks[s]; ok {
+		if c.running == s.Running && !c.stopped() {
+			return
+		}
+		c.removed = true
+	}
+	c := &scheduledCheck{
+		service: s,
+		record:  record,
+		running: s.Running,
+		host:    parseHost(s),
+	}
+	c.next = utils.Now().Add(randomJitter(sc.jitter, s.Duration()))
+	sc.checks[s] = c
+	heap.Push(&sc.queue, c)
+	sc.signal()
+}
+
+// checkNow moves the queued check of the service to the front of the queue. A check that is running
+// or waiting for its host is not moved, the service is not checked twice at the same time.
+func (sc *scheduler) checkNow(s *Service) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	c, ok := sc.checks[s]
+	if !ok || c.index < 0 {
+		return
+	}
+	c.next = utils.Now()
+	heap.Fix(&sc.queue, c.index)
+	sc.signal()
+}
+
+// signal wakes up the dispatcher to look at the head of the queue again
+func (sc *scheduler) signal() {
+	select {
+	case sc.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch hands the due checks to the workers and sleeps until the next check is due
+func (sc *scheduler) dispatch() {
+	timer := time.NewTimer(time.Hour)
+	for {
+		sc.mu.Lock()
+		now := utils.Now()
+		var ready []*scheduledCheck
+		for sc.queue.Len() > 0 && !sc.queue[0].next.After(now) {
+			c := heap.Pop(&sc.queue).(*scheduledCheck)
+			if c.stopped() {
+				sc.forget(c)
+				continue
+			}
+			if sc.inFlight[c.service] {
+				// the check of a restarted service waits for the check before the restart
+				sc.pending[c.service] = c
+				continue
+			}
+			if c.host != "" && sc.hostLimit > 0 && sc.hosts[c.host] >= sc.hostLimit {
+				sc.waiting[c.host] = append(sc.waiting[c.host], c)
+				continue
+			}
+			sc.inFlight[c.service] = true
+			sc.hosts[c.host]++
+			sc.running++
+			ready = append(ready, c)
+		}
+		wait := time.Hour
+		if sc.queue.Len() > 0 {
+			wait = sc.queue[0].next.Sub(now)
+		}
+		metrics.SchedulerStats(sc.queue.Len(), sc.running, sc.workers)
+		sc.mu.Unlock()
+
+		for _, c := range ready {
+			sc.jobs <- c
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+		select {
+		case <-sc.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// work runs the checks handed out by the dispatcher
+func (sc *scheduler) work() {
+	for c := range sc.jobs {
+		start := utils.Now()
+		metrics.SchedulerLag(start.Sub(c.next))
+		retry := sc.run(c.service, &c.attempts, c.record)
+		sc.finish(c, start, retry)
+	}
+}
+
+// finish frees the host of the check and queues the next check of the service, a failed attempt
+// that is retried is queued again after the retry delay without moving the checkpoint
+func (sc *scheduler) finish(c *scheduledCheck, start time.Time, retry bool) {
+	s := c.service
+	now := utils.Now()
+	// the interval is read from the field, a restart can start the service while its check finishes
+	if interval := time.Duration(s.Interval) * time.Second; interval > 0 && now.Sub(start) > interval {
+		metrics.Inc("overran", s.Name)
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.running--
+	delete(sc.inFlight, s)
+	if next, ok := sc.pending[s]; ok {
+		delete(sc.pending, s)
+		heap.Push(&sc.queue, next)
+	}
+	sc.hosts[c.host]--
+	if sc.hosts[c.host] <= 0 {
+		delete(sc.hosts, c.host)
+	}
+	if waiting := sc.waiting[c.host]; len(waiting) > 0 {
+		heap.Push(&sc.queue, waiting[0])
+		if len(waiting) == 1 {
+			delete(sc.waiting, c.host)
+		} else {
+			sc.waiting[c.host] = waiting[1:]
+		}
+	}
+
+	if c.stopped() {
+		log.Infof("Stopping service: %v", s.Name)
+		sc.forget(c)
+	} else if retry {
+		c.next = now.Add(s.retryDelay())
+		s.SleepDuration = c.next.Sub(now)
+		heap.Push(&sc.queue, c)
+	} else {
+		if skipped := c.reschedule(now, s.Online, s.checkInterval(now), sc.jitter); skipped > 0 {
+			metrics.Add("skipped", float64(skipped), s.Name)
+		}
+		s.Checkpoint = c.checkpoint
+		s.SleepDuration = c.next.Sub(now)
+		heap.Push(&sc.queue, c)
+	}
+	sc.signal()
+}
+
+// forget removes the service from the scheduler if the check is still the current one of the service
+func (sc *scheduler) forget(c *scheduledCheck) {
+	if sc.checks[c.service] == c {
+		delete(sc.checks, c.service)
+	}
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"container/heap"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReschedule(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	interval := 30 * time.Second
+
+	tests := []struct {
+		Name       string
+		Checkpoint time.Time
+		Online     bool
+		Finished   time.Time
+		Expected   time.Time
+		Skipped    int
+	}{
+		{"First Check", time.Time{}, true, now, now.Add(interval), 0},
+		{"Online Keeps Checkpoint", now, true, now.Add(3 * time.Second), now.Add(interval), 0},
+		{"Offline Waits Interval", now, false, now.Add(3 * time.Second), now.Add(3*time.Second + interval), 0},
+		{"Skips Missed Interval", now, true, now.Add(40 * time.Second), now.Add(2 * interval), 1},
+		{"Skips Missed Intervals", now, true, now.Add(95 * time.Second), now.Add(4 * interval), 3},
+		{"Skips Exact Checkpoint", now, true, now.Add(interval), now.Add(2 * interval), 1},
+	}
+
+	for _, v := range tests {
+		t.Run(v.Name, func(t *testing.T) {
+			c := &scheduledCheck{checkpoint: v.Checkpoint}
+			skipped := c.reschedule(v.Finished, v.Online, interval, 0)
+			assert.Equal(t, v.Skipped, skipped)
+			assert.Equal(t, v.Expected, c.checkpoint)
+			assert.Equal(t, v.Expected, c.next)
+		})
+	}
+
+	t.Run("Jitter", func(t *testing.T) {
+		c := &scheduledCheck{checkpoint: now}
+		for i := 0; i < 50; i++ {
+			c.checkpoint = now
+			c.reschedule(now, true, interval, time.Minute)
+			assert.Equal(t, now.Add(interval), c.checkpoint)
+			assert.False(t, c.next.Before(c.checkpoint))
+			assert.True(t, c.next.Before(c.checkpoint.Add(interval/10)))
+		}
+	})
+
+	t.Run("Minimum Interval", func(t *testing.T) {
+		c := &scheduledCheck{}
+		c.reschedule(now, true, 0, 0)
+		assert.Equal(t, now.Add(minInterval), c.next)
+	})
+}
+
+func TestCheckQueue(t *testing.T) {
+	now := time.Now()
+	var q checkQueue
+	for _, offset := range []int{5, 1, 4, 2, 3} {
+		heap.Push(&q, &scheduledCheck{next: now.Add(time.Duration(offset) * time.Second)})
+	}
+	for i := 1; i <= 5; i++ {
+		c := heap.Pop(&q).(*scheduledCheck)
+		assert.Equal(t, now.Add(time.Duration(i)*time.Second), c.next)
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	var mu sync.Mutex
+	checks := make(map[string]int)
+	hostRunning := make(map[string]int)
+	maxHostRunning := 0
+
+	sc := newScheduler(4, 1, time.Millisecond)
+	sc.run = func(s *Service, attempts *checkAttempts, record bool) bool {
+		mu.Lock()
+		checks[s.Name]++
+		host := parseHost(s)
+		hostRunning[host]++
+		if hostRunning[host] > maxHostRunning {
+			maxHostRunning = hostRunning[host]
+		}
+		mu.Unlock()
+
+		time.Sleep(100 * time.Millisecond)
+		s.Online = true
+
+		mu.Lock()
+		hostRunning[host]--
+		mu.Unlock()
+		return false
+	}
+
+	var all []*Service
+	for _, name := range []string{"First", "Second", "Third"} {
+		s := &Service{Name: name, Domain: "shared.example.com", Type: "tcp", Interval: 1}
+		all = append(all, s)
+	}
+	other := &Service{Name: "Other", Domain: "other.example.com", Type: "tcp", Interval: 1}
+	all = append(all, other)
+
+	for _, s := range all {
+		s.Start()
+		sc.add(s, false)
+		// adding a running service twice does not check it twice
+		sc.add(s, false)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	other.Close()
+	time.Sleep(1200 * time.Millisecond)
+	for _, s := range all {
+		s.Close()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, maxHostRunning)
+	for _, s := range all[:3] {
+		assert.GreaterOrEqual(t, checks[s.Name], 2, s.Name)
+		assert.LessOrEqual(t, checks[s.Name], 3, s.Name)
+	}
+	assert.Equal(t, 2, checks["Other"])
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	_, ok := sc.checks[other]
+	require.False(t, ok)
+}
+
+func TestSchedulerRetry(t *testing.T) {
+	sc := newScheduler(1, -1, time.Millisecond)
+	s := &Service{Name: "Retry", Domain: "retry.example.com", Type: "tcp", Interval: 60, RetryDelay: 5}
+	s.Start()
+	defer s.Close()
+
+	checkpoint := time.Now().Add(-time.Minute)
+	c := &scheduledCheck{service: s, running: s.Running, host: parseHost(s), checkpoint: checkpoint}
+	sc.checks[s] = c
+	sc.hosts[c.host] = 1
+	sc.running = 1
+
+	// a failed attempt is queued again after the retry delay instead of blocking the worker
+	sc.finish(c, time.Now(), true)
+	require.Equal(t, 1, sc.queue.Len())
+	assert.Equal(t, checkpoint, c.checkpoint)
+	assert.InDelta(t, (5 * time.Second).Seconds(), time.Until(c.next).Seconds(), 0.5)
+	assert.Equal(t, 0, sc.running)
+
+	// the last attempt schedules the next check one interval after the checkpoint
+	heap.Pop(&sc.queue)
+	sc.running, sc.hosts[c.host] = 1, 1
+	sc.finish(c, time.Now(), false)
+	assert.True(t, c.checkpoint.After(checkpoint))
+	assert.True(t, c.next.After(time.Now().Add(50*time.Second)))
+}
+
+func TestSchedulerCheckNow(t *testing.T) {
+	sc := newScheduler(1, -1, time.Millisecond)
+	s := &Service{Name: "Now", Domain: "now.example.com", Type: "tcp", Interval: 60}
+	c := &scheduledCheck{service: s, next: time.Now().Add(time.Minute)}
+	sc.checks[s] = c
+	heap.Push(&sc.queue, c)
+
+	sc.checkNow(s)
+	assert.False(t, c.next.After(time.Now()))
+
+	// a check that is running is not queued a second time
+	heap.Pop(&sc.queue)
+	sc.checkNow(s)
+	assert.Equal(t, 0, sc.queue.Len())
+}
+
+func TestSchedulerRestart(t *testing.T) {
+	var mu sync.Mutex
+	var running, maxRunning, checks int
+	started := make(chan struct{}, 10)
+
+	sc := newScheduler(4, -1, time.Millisecond)
+	sc.run = func(s *Service, attempts *checkAttempts, record bool) bool {
+		mu.Lock()
+		running++
+		checks++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		started <- struct{}{}
+
+		time.Sleep(100 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return false
+	}
+
+	s := &Service{Name: "Restarted", Domain: "restart.example.com", Type: "tcp", Interval: 60}
+	s.Start()
+	sc.add(s, false)
+	<-started
+
+	// restarting the service during the slow check queues a new check that waits for the running one
+	s.Close()
+	s.Start()
+	sc.add(s, false)
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the restarted service was not checked")
+	}
+	s.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, maxRunning)
+	assert.Equal(t, 2, checks)
+}
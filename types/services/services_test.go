@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"github.com/gorilla/mux"
 	"github.com/statping-ng/statping-ng/database"
+	"github.com/statping-ng/statping-ng/types/changes"
 	"github.com/statping-ng/statping-ng/types/checkins"
 	"github.com/statping-ng/statping-ng/types/failures"
 	"github.com/statping-ng/statping-ng/types/hits"
@@ -19,6 +20,7 @@ import (
 	pb "google.golang.org/grpc/examples/route_guide/routeguide"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
@@ -197,9 +199,10 @@ func startupDb(t *testing.T) {
 	require.Nil(t, err)
 	db, err := database.OpenTester()
 	require.Nil(t, err)
-	db.AutoMigrate(&Service{}, &notifications.Notification{}, &messages.Message{}, &hits.Hit{}, &checkins.Checkin{}, &checkins.CheckinHit{}, &failures.Failure{}, &incidents.Incident{}, &incidents.IncidentUpdate{})
+	db.AutoMigrate(&Service{}, &notifications.Notification{}, &messages.Message{}, &hits.Hit{}, &checkins.Checkin{}, &checkins.CheckinHit{}, &failures.Failure{}, &changes.Change{}, &incidents.Incident{}, &incidents.IncidentUpdate{})
 	checkins.SetDB(db)
 	failures.SetDB(db)
+	changes.SetDB(db)
 	incidents.SetDB(db)
 	notifications.SetDB(db)
 	messages.SetDB(db)
@@ -616,3 +619,29 @@ services:
 		assert.NoFileExists(t, utils.Directory+"/services.yml")
 	})
 }
+
+func TestHttpTiming(t *testing.T) {
+	utils.InitEnvs()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	s := &Service{
+		Name:           "HTTP Timing",
+		Domain:         server.URL,
+		Type:           "http",
+		Method:         "GET",
+		ExpectedStatus: 200,
+		Timeout:        2,
+	}
+	s.CheckService(false)
+	require.True(t, s.Online)
+	require.NotNil(t, s.timing)
+
+	assert.Greater(t, int64(s.timing.TcpConnect), int64(0))
+	assert.Greater(t, int64(s.timing.TlsHandshake), int64(0))
+	assert.GreaterOrEqual(t, s.timing.FirstByte, 20*time.Millisecond)
+	assert.Less(t, s.timing.FirstByte.Microseconds(), s.Latency)
+}
@@ -252,6 +252,35 @@ func TestServiceNotifications(t *testing.T) {
 		runNotifyTests(t, notif, tests...)
 	})
 
+	t.Run("Strategy #5 - Degraded - [online, warn once when degraded, notify when back online", func(t *testing.T) {
+		allNotifiers[notification.Method] = notification
+		service := Example(true)
+		service.prevOnline = true // set online during startup
+		service.DegradedLatency = 100
+		notif := notification
+		success, warnings := notif.success, notif.warnings
+
+		service.Latency = 500000
+		RecordSuccess(&service)
+		assert.True(t, service.Degraded)
+		assert.Equal(t, "degraded", service.Status())
+		assert.Equal(t, warnings+1, notif.warnings)
+		assert.Equal(t, success, notif.success)
+
+		RecordSuccess(&service)
+		assert.Equal(t, warnings+1, notif.warnings)
+
+		service.Latency = 20000
+		RecordSuccess(&service)
+		assert.False(t, service.Degraded)
+		assert.Equal(t, "online", service.Status())
+		assert.Equal(t, warnings+1, notif.warnings)
+		assert.Equal(t, success+1, notif.success)
+
+		RecordSuccess(&service)
+		assert.Equal(t, success+1, notif.success)
+	})
+
 	t.Run("Test Samples", func(t *testing.T) {
 		require.Nil(t, Samples())
 		assert.Len(t, All(), 11)
@@ -301,6 +330,7 @@ type exampleNotifier struct {
 	*notifications.Notification
 	failures int
 	success  int
+	warnings int
 	saves    int
 	tests    int
 }
@@ -315,6 +345,11 @@ func (e *exampleNotifier) OnFailure(s Service, f failures.Failure) (string, erro
 	return "", nil
 }
 
+func (e *exampleNotifier) OnWarning(s Service, f failures.Failure) (string, error) {
+	e.warnings++
+	return "", nil
+}
+
 func (e *exampleNotifier) OnSave() (string, error) {
 	e.saves++
 	return "", nil
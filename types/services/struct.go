@@ -3,11 +3,14 @@ package services
 import (
 	"time"
 
+	"github.com/statping-ng/statping-ng/types/changes"
 	"github.com/statping-ng/statping-ng/types/checkins"
 	"github.com/statping-ng/statping-ng/types/failures"
+	"github.com/statping-ng/statping-ng/types/hits"
 	"github.com/statping-ng/statping-ng/types/incidents"
 	"github.com/statping-ng/statping-ng/types/messages"
 	"github.com/statping-ng/statping-ng/types/null"
+	"github.com/statping-ng/statping-ng/utils"
 )
 
 // Service is the main struct for Services
@@ -26,6 +29,8 @@ type Service struct {
 	Order               int                   `gorm:"default:0;column:order_id" json:"order_id" yaml:"order_id"`
 	VerifySSL           null.NullBool         `gorm:"default:false;column:verify_ssl" json:"verify_ssl" scope:"user,admin" yaml:"verify_ssl"`
 	GrpcHealthCheck     null.NullBool         `gorm:"default:false;column:grpc_health_check" json:"grpc_health_check" scope:"user,admin" yaml:"grpc_health_check"`
+	GrpcHealthService   string                `gorm:"column:grpc_health_service" json:"grpc_health_service" scope:"user,admin" yaml:"grpc_health_service"`
+	GrpcMethod          string                `gorm:"column:grpc_method" json:"grpc_method" scope:"user,admin" yaml:"grpc_method"`
 	Public              null.NullBool         `gorm:"default:true;column:public" json:"public" yaml:"public"`
 	GroupId             int                   `gorm:"default:0;column:group_id" json:"group_id" yaml:"group_id"`
 	TLSCert             null.NullString       `gorm:"column:tls_cert" json:"tls_cert" scope:"user,admin" yaml:"tls_cert"`
@@ -34,9 +39,55 @@ type Service struct {
 	Headers             null.NullString       `gorm:"column:headers" json:"headers" scope:"user,admin" yaml:"headers"`
 	Permalink           null.NullString       `gorm:"column:permalink" json:"permalink" yaml:"permalink"`
 	Redirect            null.NullBool         `gorm:"default:false;column:redirect" json:"redirect" scope:"user,admin" yaml:"redirect"`
+	DnsRecordType       string                `gorm:"column:dns_record_type" json:"dns_record_type" scope:"user,admin" yaml:"dns_record_type"`
+	DnsServer           null.NullString       `gorm:"column:dns_server" json:"dns_server" scope:"user,admin" yaml:"dns_server"`
+	DnsMatch            string                `gorm:"column:dns_match" json:"dns_match" scope:"user,admin" yaml:"dns_match"`
+	DnsMinTTL           int                   `gorm:"default:0;column:dns_min_ttl" json:"dns_min_ttl" scope:"user,admin" yaml:"dns_min_ttl"`
+	DnsMaxTTL           int                   `gorm:"default:0;column:dns_max_ttl" json:"dns_max_ttl" scope:"user,admin" yaml:"dns_max_ttl"`
+	CertWarningDays     int                   `gorm:"default:0;column:cert_warning_days" json:"cert_warning_days" scope:"user,admin" yaml:"cert_warning_days"`
+	CertCriticalDays    int                   `gorm:"default:0;column:cert_critical_days" json:"cert_critical_days" scope:"user,admin" yaml:"cert_critical_days"`
+	Payload             null.NullString       `gorm:"column:payload" json:"payload" scope:"user,admin" yaml:"payload"`
+	PayloadEncoding     string                `gorm:"column:payload_encoding" json:"payload_encoding" scope:"user,admin" yaml:"payload_encoding"`
+	ExpectedPrefix      null.NullString       `gorm:"column:expected_prefix" json:"expected_prefix" scope:"user,admin" yaml:"expected_prefix"`
+	Assertions          null.NullString       `gorm:"column:assertions" json:"assertions" scope:"user,admin" yaml:"assertions"`
+	Steps               null.NullString       `gorm:"column:steps" json:"steps" scope:"user,admin" yaml:"steps"`
+	DegradedLatency     int                   `gorm:"default:0;column:degraded_latency" json:"degraded_latency" scope:"user,admin" yaml:"degraded_latency"`
+	Retries             int                   `gorm:"default:0;column:retries" json:"retries" scope:"user,admin" yaml:"retries"`
+	RetryDelay          int                   `gorm:"default:0;column:retry_delay" json:"retry_delay" scope:"user,admin" yaml:"retry_delay"`
+	DownInterval        int                   `gorm:"default:0;column:down_interval" json:"down_interval" scope:"user,admin" yaml:"down_interval"`
+	DownIntervalMax     int                   `gorm:"default:0;column:down_interval_max" json:"down_interval_max" scope:"user,admin" yaml:"down_interval_max"`
+	RecoveryInterval    int                   `gorm:"default:0;column:recovery_interval" json:"recovery_interval" scope:"user,admin" yaml:"recovery_interval"`
+	ConfirmIP           string                `gorm:"column:confirm_ip" json:"confirm_ip" scope:"user,admin" yaml:"confirm_ip"`
+	ConfirmResolver     string                `gorm:"column:confirm_resolver" json:"confirm_resolver" scope:"user,admin" yaml:"confirm_resolver"`
+	Resolver            string                `gorm:"column:resolver" json:"resolver" scope:"user,admin" yaml:"resolver"`
+	ResolveOverride     string                `gorm:"column:resolve_override" json:"resolve_override" scope:"user,admin" yaml:"resolve_override"`
+	IpFamily            string                `gorm:"column:ip_family" json:"ip_family" scope:"user,admin" yaml:"ip_family"`
+	Proxy               string                `gorm:"column:proxy" json:"proxy" scope:"user,admin" yaml:"proxy"`
+	SqlDriver           string                `gorm:"column:sql_driver" json:"sql_driver" scope:"user,admin" yaml:"sql_driver"`
+	SqlQuery            null.NullString       `gorm:"column:sql_query" json:"sql_query" scope:"user,admin" yaml:"sql_query"`
+	MqttTopic           string                `gorm:"column:mqtt_topic" json:"mqtt_topic" scope:"user,admin" yaml:"mqtt_topic"`
+	AmqpVhost           string                `gorm:"column:amqp_vhost" json:"amqp_vhost" scope:"user,admin" yaml:"amqp_vhost"`
+	AmqpQueue           string                `gorm:"column:amqp_queue" json:"amqp_queue" scope:"user,admin" yaml:"amqp_queue"`
+	AmqpProbeQueue      string                `gorm:"column:amqp_probe_queue" json:"amqp_probe_queue" scope:"user,admin" yaml:"amqp_probe_queue"`
+	SshFingerprint      string                `gorm:"column:ssh_fingerprint" json:"ssh_fingerprint" scope:"user,admin" yaml:"ssh_fingerprint"`
+	SshPrivateKey       null.NullString       `gorm:"column:ssh_private_key" json:"ssh_private_key" scope:"user,admin" yaml:"ssh_private_key"`
+	SshCommand          null.NullString       `gorm:"column:ssh_command" json:"ssh_command" scope:"user,admin" yaml:"ssh_command"`
+	LdapStartTLS        null.NullBool         `gorm:"default:false;column:ldap_start_tls" json:"ldap_start_tls" scope:"user,admin" yaml:"ldap_start_tls"`
+	LdapBaseDN          string                `gorm:"column:ldap_base_dn" json:"ldap_base_dn" scope:"user,admin" yaml:"ldap_base_dn"`
+	LdapFilter          string                `gorm:"column:ldap_filter" json:"ldap_filter" scope:"user,admin" yaml:"ldap_filter"`
+	CommandArgs         null.NullString       `gorm:"column:command_args" json:"command_args" scope:"user,admin" yaml:"command_args"`
+	CommandEnv          null.NullString       `gorm:"column:command_env" json:"command_env" scope:"user,admin" yaml:"command_env"`
+	IcmpCount           int                   `gorm:"default:1;column:icmp_count" json:"icmp_count" scope:"user,admin" yaml:"icmp_count"`
+	Diagnostics         null.NullBool         `gorm:"default:false;column:diagnostics" json:"diagnostics" scope:"user,admin" yaml:"diagnostics"`
+	ContentCheck        null.NullBool         `gorm:"default:false;column:content_check" json:"content_check" scope:"user,admin" yaml:"content_check"`
+	ContentSelector     null.NullString       `gorm:"column:content_selector" json:"content_selector" scope:"user,admin" yaml:"content_selector"`
+	ContentRegex        null.NullString       `gorm:"column:content_regex" json:"content_regex" scope:"user,admin" yaml:"content_regex"`
+	ContentIgnore       null.NullString       `gorm:"column:content_ignore" json:"content_ignore" scope:"user,admin" yaml:"content_ignore"`
 	CreatedAt           time.Time             `gorm:"column:created_at" json:"created_at" yaml:"-"`
 	UpdatedAt           time.Time             `gorm:"column:updated_at" json:"updated_at" yaml:"-"`
 	Online              bool                  `gorm:"-" json:"online" yaml:"-"`
+	Degraded            bool                  `gorm:"-" json:"degraded" yaml:"-"`
+	DegradedIssue       string                `gorm:"-" json:"degraded_issue,omitempty" scope:"user,admin" yaml:"-"`
 	Latency             int64                 `gorm:"-" json:"latency" yaml:"-"`
 	PingTime            int64                 `gorm:"-" json:"ping_time" yaml:"-"`
 	Online24Hours       float32               `gorm:"-" json:"online_24_hours" yaml:"-"`
@@ -58,13 +109,32 @@ type Service struct {
 	LastOnline          time.Time             `gorm:"-" json:"last_success" yaml:"-"`
 	LastOffline         time.Time             `gorm:"-" json:"last_error" yaml:"-"`
 	Stats               *Stats                `gorm:"-" json:"stats,omitempty" yaml:"-"`
+	Certificate         *Certificate          `gorm:"-" json:"certificate,omitempty" yaml:"-"`
+	StepResults         []*StepResult         `gorm:"-" json:"step_results,omitempty" scope:"user,admin" yaml:"-"`
+	Perfdata            hits.Perfdata         `gorm:"-" json:"perfdata,omitempty" scope:"user,admin" yaml:"-"`
+	ContentHash         string                `gorm:"-" json:"content_hash,omitempty" yaml:"-"`
 	Messages            []*messages.Message   `gorm:"foreignkey:service;association_foreignkey:id" json:"messages,omitempty" yaml:"messages"`
 	Incidents           []*incidents.Incident `gorm:"foreignkey:service;association_foreignkey:id" json:"incidents,omitempty" yaml:"incidents"`
 	Checkins            []*checkins.Checkin   `gorm:"foreignkey:service;association_foreignkey:id" json:"checkins,omitempty" yaml:"-" scope:"user,admin"`
 	Failures            []*failures.Failure   `gorm:"-" json:"failures,omitempty" yaml:"-" scope:"user,admin"`
 
-	notifyAfterCount int64 `gorm:"-" json:"-" yaml:"-"`
-	prevOnline       bool  `gorm:"-" json:"-" yaml:"-"`
+	notifyAfterCount int64              `gorm:"-" json:"-" yaml:"-"`
+	prevOnline       bool               `gorm:"-" json:"-" yaml:"-"`
+	prevDegraded     bool               `gorm:"-" json:"-" yaml:"-"`
+	certLevel        string             `gorm:"-" json:"-" yaml:"-"`
+	timing           *utils.HttpTiming  `gorm:"-" json:"-" yaml:"-"`
+	pingStats        *utils.PingStats   `gorm:"-" json:"-" yaml:"-"`
+	softFailures     []string           `gorm:"-" json:"-" yaml:"-"`
+	diagnosed        bool               `gorm:"-" json:"-" yaml:"-"`
+	attempts         *checkAttempts     `gorm:"-" json:"-" yaml:"-"`
+	confirmIP        string             `gorm:"-" json:"-" yaml:"-"`
+	resolvedIP       string             `gorm:"-" json:"-" yaml:"-"`
+	familyIP         string             `gorm:"-" json:"-" yaml:"-"`
+	familyLatencies  hits.StepLatencies `gorm:"-" json:"-" yaml:"-"`
+	wsLatencies      hits.StepLatencies `gorm:"-" json:"-" yaml:"-"`
+	lastChange       *changes.Change    `gorm:"-" json:"-" yaml:"-"`
+	downChecks       int                `gorm:"-" json:"-" yaml:"-"`
+	recoveredAt      time.Time          `gorm:"-" json:"-" yaml:"-"`
 }
 
 // ServiceOrder will reorder the services based on 'order_id' (Order)
@@ -82,14 +152,16 @@ type Stats struct {
 }
 
 type ser struct {
-	Time   time.Time
-	Online bool
+	Time     time.Time
+	Online   bool
+	Degraded bool
 }
 
 type UptimeSeries struct {
 	Start    time.Time `json:"start"`
 	End      time.Time `json:"end"`
 	Uptime   int64     `json:"uptime"`
+	Degraded int64     `json:"degraded"`
 	Downtime int64     `json:"downtime"`
 	Series   []series  `json:"series"`
 }
@@ -105,4 +177,5 @@ type series struct {
 	End      time.Time `json:"end"`
 	Duration int64     `json:"duration"`
 	Online   bool      `json:"online"`
+	Degraded bool      `json:"degraded"`
 }
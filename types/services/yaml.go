@@ -37,7 +37,7 @@ func LoadServicesYaml() (*yamlFile, error) {
 			}
 			log.Infof("Automatically creating service '%s' checking %s", svr.Name, svr.Domain)
 
-			go ServiceCheckQueue(svr, true)
+			ServiceCheckQueue(svr, true)
 		} else {
 			log.Infof("Service %s #%d, already inserted", svr.Name, serviceByHash.Id)
 		}
@@ -59,6 +59,10 @@ func InitEnvs() {
 	Params.SetDefault("LOGS_MAX_AGE", 28)
 	Params.SetDefault("LOGS_MAX_SIZE", 16)
 	Params.SetDefault("DISABLE_COLORS", false)
+	Params.SetDefault("SERVICE_PROXY", "")
+	Params.SetDefault("SCHEDULER_WORKERS", 100)
+	Params.SetDefault("SCHEDULER_HOST_LIMIT", 10)
+	Params.SetDefault("SCHEDULER_JITTER", 2*time.Second)
 
 	dbConn := Params.GetString("DB_CONN")
 	dbInt := Params.GetInt("DB_PORT")
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+)
+
+// htmlText appends the text of the node and its children, each text node on its own line with
+// the whitespace collapsed. Scripts and styles are skipped.
+func htmlText(n *html.Node, lines []string) []string {
+	switch {
+	case n.Type == html.TextNode:
+		if text := strings.Join(strings.Fields(n.Data), " "); text != "" {
+			lines = append(lines, text)
+		}
+		return lines
+	case n.Type == html.ElementNode && (n.Data == "script" || n.Data == "style" || n.Data == "noscript"):
+		return lines
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		lines = htmlText(c, lines)
+	}
+	return lines
+}
+
+// SelectText returns the text of the HTML elements that match the CSS selector, one line for each
+// text node. Several selectors can be separated by commas.
+func SelectText(body []byte, selector string) (string, error) {
+	sel, err := cascadia.ParseGroup(selector)
+	if err != nil {
+		return "", fmt.Errorf("invalid selector '%s': %v", selector, err)
+	}
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	var matched bool
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if sel.Match(n) {
+			matched = true
+			// the text of the children is already included with the element
+			lines = htmlText(n, lines)
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	if !matched {
+		return "", fmt.Errorf("selector '%s' did not match any element", selector)
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// DialFunc connects to the address on the named network
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// ProxyDialer returns a DialFunc that connects through the proxy URL. Proxies like 'http://proxy:3128' and
+// 'https://proxy:3128' open a tunnel with a CONNECT request, 'socks5://proxy:1080' connects with SOCKS5.
+// The user and password of the URL are used to authenticate with the proxy.
+func ProxyDialer(proxyURL string, dialer *net.Dialer) (DialFunc, error) {
+	u, err := url.Parse(strings.TrimSpace(proxyURL))
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL '%s', %v", proxyURL, err)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid proxy URL '%s', use http://, https:// or socks5://host:port", proxyURL)
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), map[string]string{"http": "80", "https": "443", "socks5": "1080", "socks5h": "1080"}[scheme])
+	}
+
+	switch scheme {
+	case "http", "https":
+		return func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return connectTunnel(ctx, dialer, u, host, network, addr)
+		}, nil
+	case "socks5", "socks5h":
+		var auth *proxy.Auth
+		if u.User != nil {
+			password, _ := u.User.Password()
+			auth = &proxy.Auth{User: u.User.Username(), Password: password}
+		}
+		socks, err := proxy.SOCKS5("tcp", host, auth, dialer)
+		if err != nil {
+			return nil, err
+		}
+		return socks.(proxy.ContextDialer).DialContext, nil
+	default:
+		return nil, fmt.Errorf("unknown proxy scheme '%s', use http://, https:// or socks5://", u.Scheme)
+	}
+}
+
+// connectTunnel connects to the proxy and asks it to open a tunnel to the address with a CONNECT request
+func connectTunnel(ctx context.Context, dialer *net.Dialer, u *url.URL, host, network, addr string) (net.Conn, error) {
+	if network != "tcp" && network != "tcp4" && network != "tcp6" {
+		return nil, fmt.Errorf("proxy cannot connect to %s address %s", network, addr)
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else if dialer.Timeout != 0 {
+		conn.SetDeadline(time.Now().Add(dialer.Timeout))
+	}
+
+	if strings.ToLower(u.Scheme) == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	req := &http.Request{
+		Method: "CONNECT",
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	req.Header.Set("User-Agent", "Statping-ng")
+	if u.User != nil {
+		password, _ := u.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy responded with '%s' to CONNECT %s", resp.Status, addr)
+	}
+	conn.SetDeadline(time.Time{})
+
+	// the target may have already sent data behind the response of the proxy
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn reads the data buffered while reading the CONNECT response before reading from the connection
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (b *bufferedConn) Read(p []byte) (int, error) {
+	return b.reader.Read(p)
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+type timingKey struct{}
+
+// HttpTiming contains the duration of each phase of a HTTP request. When the request
+// followed redirects, the phases are from the last request.
+type HttpTiming struct {
+	DnsLookup       time.Duration `json:"dns_lookup"`
+	TcpConnect      time.Duration `json:"tcp_connect"`
+	TlsHandshake    time.Duration `json:"tls_handshake"`
+	FirstByte       time.Duration `json:"first_byte"`
+	ContentTransfer time.Duration `json:"content_transfer"`
+
+	mu                               sync.Mutex
+	dnsStart, connectStart, tlsStart time.Time
+	gotConn, firstByte               time.Time
+}
+
+// withTiming returns a context that records the phases of the HTTP request into timing
+func withTiming(ctx context.Context, timing *HttpTiming) context.Context {
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			timing.mark(&timing.dnsStart)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			timing.since(&timing.DnsLookup, &timing.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			timing.mark(&timing.connectStart)
+		},
+		ConnectDone: func(string, string, error) {
+			timing.since(&timing.TcpConnect, &timing.connectStart)
+		},
+		TLSHandshakeStart: func() {
+			timing.mark(&timing.tlsStart)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			timing.since(&timing.TlsHandshake, &timing.tlsStart)
+		},
+		GotConn: func(httptrace.GotConnInfo) {
+			timing.mark(&timing.gotConn)
+		},
+		GotFirstResponseByte: func() {
+			timing.mark(&timing.firstByte)
+			timing.since(&timing.FirstByte, &timing.gotConn)
+		},
+	}
+	ctx = context.WithValue(ctx, timingKey{}, timing)
+	return httptrace.WithClientTrace(ctx, trace)
+}
+
+func (t *HttpTiming) mark(at *time.Time) {
+	t.mu.Lock()
+	*at = Now()
+	t.mu.Unlock()
+}
+
+func (t *HttpTiming) since(dur *time.Duration, start *time.Time) {
+	t.mu.Lock()
+	if !start.IsZero() {
+		*dur = Now().Sub(*start)
+	}
+	t.mu.Unlock()
+}
+
+// transferDone records the content transfer phase once the response body has been read
+func (t *HttpTiming) transferDone() {
+	t.mu.Lock()
+	if !t.firstByte.IsZero() {
+		t.ContentTransfer = Now().Sub(t.firstByte)
+	}
+	t.mu.Unlock()
+}
+
+// RequestTiming returns the timing of a response from HttpRequest, or nil if the request was not timed
+func RequestTiming(res *http.Response) *HttpTiming {
+	if res == nil || res.Request == nil {
+		return nil
+	}
+	timing, _ := res.Request.Context().Value(timingKey{}).(*HttpTiming)
+	return timing
+}
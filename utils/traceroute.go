@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// tracePort is the first destination port of the traceroute probes, each TTL uses the next port
+const tracePort = 33434
+
+// TraceHop is a router on the path to an address. The Address is empty when no reply was received
+// within the timeout, Latency is the round trip time of the probe in microseconds.
+type TraceHop struct {
+	TTL         int    `json:"ttl"`
+	Address     string `json:"address,omitempty"`
+	Latency     int64  `json:"latency,omitempty"`
+	Reached     bool   `json:"reached,omitempty"`
+	Unreachable bool   `json:"unreachable,omitempty"`
+}
+
+// Traceroute sends a UDP probe for each TTL up to maxHops at the same time, like mtr, and returns the hops
+// until the address replied or a router reported it unreachable. It doesn't need a privileged raw socket.
+func Traceroute(address string, maxHops int, timeout time.Duration) ([]TraceHop, error) {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil, fmt.Errorf("'%s' is not an IP address", address)
+	}
+
+	hops := make([]TraceHop, maxHops)
+	errs := make([]error, maxHops)
+	var wg sync.WaitGroup
+	for ttl := 1; ttl <= maxHops; ttl++ {
+		wg.Add(1)
+		go func(ttl int) {
+			defer wg.Done()
+			hops[ttl-1], errs[ttl-1] = traceProbe(ip, ttl, timeout)
+		}(ttl)
+	}
+	wg.Wait()
+
+	for i, hop := range hops {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if hop.Reached || hop.Unreachable {
+			return hops[:i+1], nil
+		}
+	}
+	return hops, nil
+}
@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"errors"
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+// origins of the extended socket errors, from linux/errqueue.h
+const (
+	soEeOriginIcmp  = 2
+	soEeOriginIcmp6 = 3
+)
+
+// traceProbe sends a UDP datagram with the TTL to the address. The socket has IP_RECVERR enabled,
+// so the ICMP time exceeded or port unreachable reply can be read from its error queue.
+func traceProbe(ip net.IP, ttl int, timeout time.Duration) (TraceHop, error) {
+	hop := TraceHop{TTL: ttl}
+	conn, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: ip, Port: tracePort + ttl})
+	if err != nil {
+		return hop, err
+	}
+	defer conn.Close()
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return hop, err
+	}
+
+	var sockErr error
+	err = raw.Control(func(fd uintptr) {
+		if ip.To4() != nil {
+			if sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TTL, ttl); sockErr == nil {
+				sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_RECVERR, 1)
+			}
+		} else {
+			if sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_UNICAST_HOPS, ttl); sockErr == nil {
+				sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_RECVERR, 1)
+			}
+		}
+	})
+	if err == nil {
+		err = sockErr
+	}
+	if err != nil {
+		return hop, err
+	}
+
+	conn.SetDeadline(time.Now().Add(timeout))
+	start := time.Now()
+	if _, err := conn.Write([]byte("statping")); err != nil {
+		return hop, err
+	}
+
+	buf := make([]byte, 512)
+	oob := make([]byte, 512)
+	var recvErr error
+	err = raw.Read(func(fd uintptr) bool {
+		_, oobn, _, _, err := syscall.Recvmsg(int(fd), buf, oob, syscall.MSG_ERRQUEUE)
+		if err == syscall.EAGAIN {
+			return false
+		}
+		if err != nil {
+			recvErr = err
+			return true
+		}
+		hop.Latency = time.Since(start).Microseconds()
+		recvErr = parseTraceReply(&hop, oob[:oobn])
+		return true
+	})
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return hop, nil
+	}
+	if err != nil {
+		return hop, err
+	}
+	return hop, recvErr
+}
+
+// parseTraceReply reads the sock_extended_err control message with the ICMP reply and the address of the router
+func parseTraceReply(hop *TraceHop, oob []byte) error {
+	messages, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return err
+	}
+	for _, m := range messages {
+		ipv4 := m.Header.Level == syscall.IPPROTO_IP && m.Header.Type == syscall.IP_RECVERR
+		ipv6 := m.Header.Level == syscall.IPPROTO_IPV6 && m.Header.Type == syscall.IPV6_RECVERR
+		if !(ipv4 || ipv6) || len(m.Data) < 16 {
+			continue
+		}
+		origin, icmpType, code, offender := m.Data[4], m.Data[5], m.Data[6], m.Data[16:]
+		switch origin {
+		case soEeOriginIcmp:
+			if len(offender) >= 8 {
+				hop.Address = net.IP(offender[4:8]).String()
+			}
+			// 11 is time exceeded, 3 is destination unreachable with code 3 for port unreachable
+			if icmpType == 3 {
+				hop.Reached = code == 3
+				hop.Unreachable = code != 3
+			}
+		case soEeOriginIcmp6:
+			if len(offender) >= 24 {
+				hop.Address = net.IP(offender[8:24]).String()
+			}
+			// 3 is time exceeded, 1 is destination unreachable with code 4 for port unreachable
+			if icmpType == 1 {
+				hop.Reached = code == 4
+				hop.Unreachable = code != 4
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package utils
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+func traceProbe(ip net.IP, ttl int, timeout time.Duration) (TraceHop, error) {
+	return TraceHop{TTL: ttl}, errors.New("traceroute is only supported on linux")
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"errors"
@@ -14,7 +15,6 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/go-ping/ping"
@@ -83,63 +83,30 @@ func ToString(s interface{}) string {
 //
 //	in, out, err := Command("sass assets/scss assets/css/base.css")
 func Command(name string, args ...string) (string, string, error) {
-	testCmd := exec.Command(name, args...)
-	var stdout, stderr []byte
-	var errStdout, errStderr error
-	stdoutIn, _ := testCmd.StdoutPipe()
-	stderrIn, _ := testCmd.StderrPipe()
-	err := testCmd.Start()
-	if err != nil {
-		return "", "", err
-	}
-
-	var wg sync.WaitGroup
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		stdout, errStdout = copyAndCapture(os.Stdout, stdoutIn)
-	}()
-
-	stderr, errStderr = copyAndCapture(os.Stderr, stderrIn)
-
-	// call testCmd.Wait() only after reads from all pipes have completed
-	wg.Wait()
-	err = testCmd.Wait()
-	if err != nil {
-		return string(stdout), string(stderr), err
-	}
+	return runCommand(exec.Command(name, args...), os.Stdout, os.Stderr)
+}
 
-	if errStdout != nil || errStderr != nil {
-		return string(stdout), string(stderr), errors.New("failed to capture stdout or stderr")
+// CommandContext will run an executable with the extra environment variables until the context is done,
+// and return stdout and errOut as strings without copying them to the terminal
+//
+//	out, errOut, err := CommandContext(ctx, []string{"LANG=C"}, "/usr/lib/nagios/plugins/check_load", "-w", "5,4,3")
+func CommandContext(ctx context.Context, env []string, name string, args ...string) (string, string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
 	}
-
-	outStr, errStr := string(stdout), string(stderr)
-	return outStr, errStr, err
+	// don't wait for child processes that keep the output open after the command was killed
+	cmd.WaitDelay = time.Second
+	return runCommand(cmd, ioutil.Discard, ioutil.Discard)
 }
 
-// copyAndCapture will read a terminal command into bytes
-func copyAndCapture(w io.Writer, r io.Reader) ([]byte, error) {
-	var out []byte
-	buf := make([]byte, 1024, 1024)
-	for {
-		n, err := r.Read(buf[:])
-		if n > 0 {
-			d := buf[:n]
-			out = append(out, d...)
-			_, err := w.Write(d)
-			if err != nil {
-				return out, err
-			}
-		}
-		if err != nil {
-			// Read returns io.EOF at the end of file, which is not an error for us
-			if err == io.EOF {
-				err = nil
-			}
-			return out, err
-		}
-	}
+// runCommand will run the command and capture stdout and errOut while copying them to the writers
+func runCommand(cmd *exec.Cmd, outW, errW io.Writer) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = io.MultiWriter(&stdout, outW)
+	cmd.Stderr = io.MultiWriter(&stderr, errW)
+	err := cmd.Run()
+	return stdout.String(), stderr.String(), err
 }
 
 // DurationReadable will return a time.Duration into a human readable string
@@ -162,6 +129,8 @@ func DurationReadable(d time.Duration) string {
 // // method - GET, POST, DELETE, PATCH
 // // content - The HTTP request content type (text/plain, application/json, or nil)
 // // headers - An array of Headers to be sent (KEY=VALUE) []string{"Authentication=12345", ...}
+// // a 'Connect-To=IP' header connects to the IP address instead of the URL host
+// // a 'Proxy=URL' header connects through a http://, https:// or socks5:// proxy instead of HTTP_PROXY
 // // body - The body or form data to send with HTTP request
 // // timeout - Specific duration to timeout on. time.Duration(30 * time.Seconds)
 // // You can use a HTTP Proxy if you HTTP_PROXY environment variable
@@ -175,6 +144,8 @@ func HttpRequest(endpoint, method string, contentType interface{}, headers []str
 	if req, err = http.NewRequest(method, endpoint, body); err != nil {
 		return nil, nil, err
 	}
+	timing := &HttpTiming{}
+	req = req.WithContext(withTiming(req.Context(), timing))
 	// set default headers so end user can overwrite them if needed
 	req.Header.Set("User-Agent", "Statping-ng")
 	req.Header.Set("Statping-Version", Params.GetString("VERSION"))
@@ -185,6 +156,7 @@ func HttpRequest(endpoint, method string, contentType interface{}, headers []str
 	}
 
 	verifyHost := req.URL.Hostname()
+	var connectTo, proxyURL string
 	for _, h := range headers {
 		keyVal := strings.SplitN(h, "=", 2)
 		if len(keyVal) == 2 {
@@ -192,6 +164,12 @@ func HttpRequest(endpoint, method string, contentType interface{}, headers []str
 				if strings.ToLower(keyVal[0]) == "host" {
 					req.Host = strings.TrimSpace(keyVal[1])
 					verifyHost = req.Host
+				} else if strings.ToLower(keyVal[0]) == "connect-to" {
+					// connect to this IP address instead of the address of the URL host
+					connectTo = strings.TrimSpace(keyVal[1])
+				} else if strings.ToLower(keyVal[0]) == "proxy" {
+					// send the request through this proxy instead of the proxy of the environment
+					proxyURL = strings.TrimSpace(keyVal[1])
 				} else {
 					req.Header.Set(keyVal[0], keyVal[1])
 				}
@@ -204,6 +182,14 @@ func HttpRequest(endpoint, method string, contentType interface{}, headers []str
 		Timeout:   timeout,
 		KeepAlive: timeout,
 	}
+	dial := DialFunc(dialer.DialContext)
+	proxyFunc := http.ProxyFromEnvironment
+	if proxyURL != "" {
+		if dial, err = ProxyDialer(proxyURL, dialer); err != nil {
+			return nil, nil, err
+		}
+		proxyFunc = nil
+	}
 
 	transport := &http.Transport{
 		TLSClientConfig: &tls.Config{
@@ -214,10 +200,13 @@ func HttpRequest(endpoint, method string, contentType interface{}, headers []str
 		DisableKeepAlives:     true,
 		ResponseHeaderTimeout: timeout,
 		TLSHandshakeTimeout:   timeout,
-		Proxy:                 http.ProxyFromEnvironment,
+		Proxy:                 proxyFunc,
 		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
 			// redirect all connections to host specified in url
-			return dialer.DialContext(ctx, network, addr)
+			if host, port, err := net.SplitHostPort(addr); err == nil && connectTo != "" && host == req.URL.Hostname() {
+				addr = net.JoinHostPort(connectTo, port)
+			}
+			return dial(ctx, network, addr)
 		},
 	}
 	if customTLS != nil {
@@ -244,6 +233,7 @@ func HttpRequest(endpoint, method string, contentType interface{}, headers []str
 	if err != nil {
 		return nil, resp, err
 	}
+	timing.transferDone()
 
 	// record HTTP metrics
 	metrics.Histo("bytes", float64(len(contents)), endpoint, method)
@@ -252,30 +242,71 @@ func HttpRequest(endpoint, method string, contentType interface{}, headers []str
 	return contents, resp, err
 }
 
-func Ping(address string, secondsTimeout int) (int64, error) {
-	ping, err := ping.NewPinger(address)
+// PingInterval is the time between the echo requests of a ping burst
+const PingInterval = 200 * time.Millisecond
+
+// PingStats are the statistics of a burst of ICMP echo requests, the round trip times are in microseconds
+// and PacketLoss is the percentage of echo requests that did not get a reply.
+type PingStats struct {
+	Sent       int
+	Received   int
+	PacketLoss float64
+	MinRtt     int64
+	AvgRtt     int64
+	MaxRtt     int64
+	Jitter     int64
+}
 
+func Ping(address string, secondsTimeout int) (int64, error) {
+	stats, err := PingBurst(address, 1, secondsTimeout)
 	if err != nil {
 		return 0, err
 	}
+	return stats.MinRtt, nil
+}
+
+// PingBurst sends count ICMP echo requests to the address and returns the statistics of the replies.
+// The timeout is extended by the interval between the requests, an error is only returned if no reply was received.
+func PingBurst(address string, count int, secondsTimeout int) (*PingStats, error) {
+	pinger, err := ping.NewPinger(address)
+	if err != nil {
+		return nil, err
+	}
+	if count < 1 {
+		count = 1
+	}
 
-	ping.Count = 1
-	ping.Timeout = time.Second * time.Duration(secondsTimeout)
+	pinger.Count = count
+	pinger.Interval = PingInterval
+	pinger.Timeout = time.Second*time.Duration(secondsTimeout) + PingInterval*time.Duration(count-1)
 
 	if runtime.GOOS == "windows" {
-		ping.SetPrivileged(true)
+		pinger.SetPrivileged(true)
 	}
 
-	err = ping.Run()
-	if err != nil {
-		return 0, err
+	if err = pinger.Run(); err != nil {
+		return nil, err
 	}
 
-	stats := ping.Statistics()
-
-	if stats.PacketsSent-stats.PacketsRecv != 0 {
-		return 0, errors.New("destination host unreachable")
+	stats := pingStats(pinger.Statistics())
+	if stats.Received == 0 {
+		return stats, errors.New("destination host unreachable")
 	}
+	return stats, nil
+}
 
-	return stats.MinRtt.Microseconds(), nil
+// pingStats converts the statistics of the pinger, the jitter is the standard deviation of the round trip times
+func pingStats(stats *ping.Statistics) *PingStats {
+	result := &PingStats{
+		Sent:     stats.PacketsSent,
+		Received: stats.PacketsRecv,
+		MinRtt:   stats.MinRtt.Microseconds(),
+		AvgRtt:   stats.AvgRtt.Microseconds(),
+		MaxRtt:   stats.MaxRtt.Microseconds(),
+		Jitter:   stats.StdDevRtt.Microseconds(),
+	}
+	if stats.PacketsSent > 0 && stats.PacketsRecv < stats.PacketsSent {
+		result.PacketLoss = float64(stats.PacketsSent-stats.PacketsRecv) / float64(stats.PacketsSent) * 100
+	}
+	return result
 }
@@ -5,9 +5,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"runtime"
 	"testing"
 	"time"
 
+	"github.com/go-ping/ping"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -223,3 +225,32 @@ func TestPing(t *testing.T) {
 	assert.Nil(t, error)
 	assert.NotEqual(t, 0, duration)
 }
+
+func TestPingStats(t *testing.T) {
+	stats := pingStats(&ping.Statistics{
+		PacketsSent: 5,
+		PacketsRecv: 4,
+		MinRtt:      10 * time.Millisecond,
+		AvgRtt:      12 * time.Millisecond,
+		MaxRtt:      15 * time.Millisecond,
+		StdDevRtt:   1500 * time.Microsecond,
+	})
+	assert.Equal(t, &PingStats{Sent: 5, Received: 4, PacketLoss: 20, MinRtt: 10000, AvgRtt: 12000, MaxRtt: 15000, Jitter: 1500}, stats)
+
+	stats = pingStats(&ping.Statistics{PacketsSent: 3})
+	assert.Equal(t, float64(100), stats.PacketLoss)
+}
+
+func TestTraceroute(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("traceroute is only supported on linux")
+	}
+	hops, err := Traceroute("127.0.0.1", 5, time.Second)
+	require.Nil(t, err)
+	require.Len(t, hops, 1)
+	assert.Equal(t, "127.0.0.1", hops[0].Address)
+	assert.True(t, hops[0].Reached)
+
+	_, err = Traceroute("localhost", 5, time.Second)
+	assert.NotNil(t, err)
+}